@@ -0,0 +1,108 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/splunk/tarunner/internal/collector"
+	"github.com/splunk/tarunner/internal/conf"
+)
+
+// maskedValue replaces the values of secrets in the output of btool.
+const maskedValue = "********"
+
+func btoolCommand(args []string) int {
+	var f commonFlags
+	fs := newFlagSet("btool", &f)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "usage: %s btool [flags] <conf> list [stanza] <basedir|archive>\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	debug := fs.Bool("debug", false, "print the file and line setting each stanza and key")
+	app := fs.String("app", "", "only print the configuration of this TA")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return parseExitCode(err)
+	}
+	if len(positional) < 3 || len(positional) > 4 || positional[1] != "list" {
+		fs.Usage()
+		return exitConfigError
+	}
+	name, basedir := positional[0], positional[len(positional)-1]
+	stanza := ""
+	if len(positional) == 4 {
+		stanza = positional[2]
+	}
+	logger, err := f.newLogger()
+	if err != nil {
+		log.Printf("invalid log level: %v", err)
+		return exitConfigError
+	}
+	cfg, err := f.loadConfig(basedir)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+	dir, opts, err := f.prepare(basedir, logger)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+	files, err := collector.ReadConf(dir, cfg, name, opts...)
+	if err != nil {
+		log.Printf("invalid configuration: %v", err)
+		return exitConfigError
+	}
+	if err = printConf(os.Stdout, files, *app, stanza, *debug); err != nil {
+		log.Print(err)
+		return exitRuntimeError
+	}
+	return 0
+}
+
+// printConf writes the stanzas of configuration files like Splunk btool, masking secrets.
+// With debug, the stanzas of the default files shadowed by local files follow, marked as overridden.
+func printConf(out io.Writer, files []collector.ConfFile, app string, stanza string, debug bool) error {
+	w := tabwriter.NewWriter(out, 0, 4, 1, ' ', 0)
+	for _, file := range files {
+		if app != "" && file.App != app {
+			continue
+		}
+		if !debug {
+			printSections(w, file.Sections, stanza, func(int) string { return "" })
+			continue
+		}
+		printSections(w, file.Sections, stanza, func(line int) string {
+			return fmt.Sprintf("%s:%d\t", file.Path, line)
+		})
+		if file.Overridden != nil {
+			printSections(w, file.Overridden.Sections, stanza, func(line int) string {
+				return fmt.Sprintf("%s:%d (overridden)\t", file.Overridden.Path, line)
+			})
+		}
+	}
+	return w.Flush()
+}
+
+// printSections writes the stanzas named stanza, or all stanzas, each line prefixed by the origin of its line.
+func printSections(w io.Writer, sections []conf.Section, stanza string, origin func(line int) string) {
+	for _, s := range sections {
+		if stanza != "" && s.Name != stanza {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s[%s]\n", origin(s.Line), s.Name)
+		for _, setting := range s.Settings {
+			value := setting.Value
+			if conf.IsSecret(setting.Key) && value != "" {
+				value = maskedValue
+			}
+			_, _ = fmt.Fprintf(w, "%s%s = %s\n", origin(setting.Line), setting.Key, value)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/tarunner/internal/collector"
+	"github.com/splunk/tarunner/internal/conf"
+)
+
+func TestPrintConf(t *testing.T) {
+	files := []collector.ConfFile{{
+		App:  "app",
+		Path: "local/outputs.conf",
+		Sections: []conf.Section{{Name: "tcpout", Line: 1, Settings: []conf.Setting{
+			{Key: "server", Value: "idx:9997", Line: 2},
+			{Key: "sslPassword", Value: "secret", Line: 3},
+		}}},
+		Overridden: &collector.ConfFile{
+			App:      "app",
+			Path:     "default/outputs.conf",
+			Sections: []conf.Section{{Name: "tcpout", Line: 4, Settings: []conf.Setting{{Key: "compressed", Value: "true", Line: 5}}}},
+		},
+	}}
+	var out bytes.Buffer
+	require.NoError(t, printConf(&out, files, "", "", false))
+	assert.Equal(t, "[tcpout]\nserver = idx:9997\nsslPassword = ********\n", out.String())
+
+	out.Reset()
+	require.NoError(t, printConf(&out, files, "", "", true))
+	assert.Equal(t, `local/outputs.conf:1                [tcpout]
+local/outputs.conf:2                server = idx:9997
+local/outputs.conf:3                sslPassword = ********
+default/outputs.conf:4 (overridden) [tcpout]
+default/outputs.conf:5 (overridden) compressed = true
+`, out.String())
+}
@@ -0,0 +1,52 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/splunk/tarunner/internal/splunksecret"
+)
+
+func encryptCommand(args []string) int {
+	fs := flag.NewFlagSet("encrypt", flag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "usage: %s encrypt --splunk-secret <path> [value]\n\nEncrypts the value, or the first line of stdin, as a $7$ value.\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	secretPath := fs.String("splunk-secret", "", "path to the splunk.secret file, such as $SPLUNK_HOME/etc/auth/splunk.secret")
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	if *secretPath == "" || fs.NArg() > 1 {
+		fs.Usage()
+		return exitConfigError
+	}
+	secret, err := splunksecret.Read(*secretPath)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+	// Reading the value from stdin keeps it out of the shell history.
+	value := fs.Arg(0)
+	if fs.NArg() == 0 {
+		if value, err = bufio.NewReader(os.Stdin).ReadString('\n'); err != nil && value == "" {
+			log.Printf("failed to read the value from stdin: %v", err)
+			return exitConfigError
+		}
+		value = strings.TrimRight(value, "\r\n")
+	}
+	encrypted, err := secret.Encrypt(value)
+	if err != nil {
+		log.Print(err)
+		return exitRuntimeError
+	}
+	_, _ = fmt.Fprintln(os.Stdout, encrypted)
+	return 0
+}
@@ -0,0 +1,192 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/collector/featuregate"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/splunk/tarunner/internal/archive"
+	"github.com/splunk/tarunner/internal/collector"
+	"github.com/splunk/tarunner/internal/config"
+)
+
+// commonFlags holds the flags shared by the commands operating on a TA.
+type commonFlags struct {
+	configFile string
+	logLevel   string
+	workDir    string
+	localDir   string
+	systemDir  string
+	// splunkSecret overrides the splunk_secret key of tarunner.yaml.
+	splunkSecret string
+}
+
+func newFlagSet(name string, f *commonFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "usage: %s %s [flags] <basedir|archive>\n\nFlags:\n", os.Args[0], name)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&f.configFile, "config", "", "path to the tarunner configuration file (default <basedir>/tarunner.yaml)")
+	fs.StringVar(&f.logLevel, "log-level", "info", "log level: debug, info, warn or error")
+	fs.StringVar(&f.workDir, "work-dir", "", "folder where a packaged TA is extracted (default <user cache dir>/tarunner/<archive name>)")
+	fs.StringVar(&f.localDir, "local-dir", "", "folder holding the local configuration files of the TA (default the local folder of the TA, or the local folder next to a packaged TA)")
+	fs.StringVar(&f.systemDir, "system-dir", "", "folder holding an outputs.conf file taking precedence over the outputs.conf files of the TAs, such as $SPLUNK_HOME/etc/system/local")
+	fs.StringVar(&f.splunkSecret, "splunk-secret", "", "path to the splunk.secret file decrypting $7$ and $1$ values, such as $SPLUNK_HOME/etc/auth/splunk.secret (default the splunk_secret key of tarunner.yaml)")
+	featuregate.GlobalRegistry().RegisterFlags(fs)
+	// --feature-flags is the name documented since the first releases, kept as an alias of --feature-gates.
+	fs.Var(fs.Lookup("feature-gates").Value, "feature-flags", "alias of --feature-gates")
+	return fs
+}
+
+// selectionFlags holds the --only and --exclude flags selecting the inputs to run.
+type selectionFlags struct {
+	only    patternsFlag
+	exclude patternsFlag
+}
+
+func (f *selectionFlags) register(fs *flag.FlagSet) {
+	fs.Var(&f.only, "only", "only run the inputs whose stanza name matches this shell pattern, such as script://./bin/*.sh (repeatable)\n"+
+		"* does not match /: script://* matches no script, use script://./bin/*")
+	fs.Var(&f.exclude, "exclude", "do not run the inputs whose stanza name matches this shell pattern, where * does not match / (repeatable)")
+}
+
+func (f *selectionFlags) option() collector.Option {
+	return collector.WithSelection(f.only, f.exclude)
+}
+
+// patternsFlag collects the shell patterns of a repeatable flag.
+type patternsFlag []string
+
+func (p *patternsFlag) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *patternsFlag) Set(value string) error {
+	if _, err := path.Match(value, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", value, err)
+	}
+	*p = append(*p, value)
+	return nil
+}
+
+// parseExitCode returns the exit code of a command whose arguments failed to parse: 0 if help was requested with -h.
+func parseExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	return exitConfigError
+}
+
+// parse parses the arguments of a command and returns the TA base directory.
+func parse(fs *flag.FlagSet, args []string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return "", errors.New("expected exactly one base directory argument")
+	}
+	return fs.Arg(0), nil
+}
+
+// parseInterspersed parses the arguments of a command accepting flags between its positional arguments,
+// and returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// loadConfig loads the tarunner configuration file. Unless --config is set, the file is optional and an empty
+// configuration is returned if it does not exist, the exporters being declared by outputs.conf.
+// --splunk-secret overrides the splunk_secret key of the file.
+func (f *commonFlags) loadConfig(basedir string) (*config.Config, error) {
+	configFile := f.configPath(basedir)
+	cfg := &config.Config{}
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		if f.configFile != "" {
+			return nil, fmt.Errorf("config file %q does not exist", configFile)
+		}
+	} else if cfg, err = config.LoadConfig(configFile); err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if f.splunkSecret != "" {
+		cfg.SplunkSecret = f.splunkSecret
+	}
+	return cfg, nil
+}
+
+func (f *commonFlags) configPath(basedir string) string {
+	if f.configFile != "" {
+		return f.configFile
+	}
+	if archive.IsArchive(basedir) {
+		return filepath.Join(filepath.Dir(basedir), "tarunner.yaml")
+	}
+	return filepath.Join(basedir, "tarunner.yaml")
+}
+
+// prepare returns the folder of the TAs to run and the options of the collector.
+// A packaged TA is extracted to the work directory first, its local configuration being read from
+// a separate folder, so the package can be upgraded by replacing the archive.
+func (f *commonFlags) prepare(basedir string, logger *zap.Logger) (string, []collector.Option, error) {
+	opts := []collector.Option{collector.WithLogger(logger)}
+	if f.systemDir != "" {
+		opts = append(opts, collector.WithSystemDir(f.systemDir))
+	}
+	if !archive.IsArchive(basedir) {
+		if f.localDir != "" {
+			opts = append(opts, collector.WithLocalDir(f.localDir))
+		}
+		return basedir, opts, nil
+	}
+
+	workDir := f.workDir
+	if workDir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			cacheDir = os.TempDir()
+		}
+		workDir = filepath.Join(cacheDir, "tarunner", archive.Name(basedir))
+	}
+	dir, err := archive.Extract(basedir, workDir)
+	if err != nil {
+		return "", nil, err
+	}
+	localDir := f.localDir
+	if localDir == "" {
+		localDir = filepath.Join(filepath.Dir(basedir), "local")
+	}
+	logger.Info("Using packaged TA", zap.String("archive", basedir), zap.String("dir", dir), zap.String("local", localDir))
+	return dir, append(opts, collector.WithLocalDir(localDir)), nil
+}
+
+func (f *commonFlags) newLogger() (*zap.Logger, error) {
+	level, err := zapcore.ParseLevel(f.logLevel)
+	if err != nil {
+		return nil, err
+	}
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(level)
+	return cfg.Build()
+}
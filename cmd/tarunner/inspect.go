@@ -0,0 +1,103 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/splunk/tarunner/internal/collector"
+)
+
+func inspectCommand(args []string) int {
+	var f commonFlags
+	fs := newFlagSet("inspect", &f)
+	format := fs.String("format", "text", "output format: text or json")
+	failOn := fs.String("fail-on", "none", "exit with code 4 if a stanza is at most this supported: none, unsupported or partial")
+	basedir, err := parse(fs, args)
+	if err != nil {
+		return parseExitCode(err)
+	}
+	if *format != "text" && *format != "json" {
+		log.Printf("invalid format %q", *format)
+		return exitConfigError
+	}
+	if *failOn != "none" && *failOn != collector.Unsupported && *failOn != collector.Partial {
+		log.Printf("invalid --fail-on value %q", *failOn)
+		return exitConfigError
+	}
+	logger, err := f.newLogger()
+	if err != nil {
+		log.Printf("invalid log level: %v", err)
+		return exitConfigError
+	}
+	// tarunner.yaml is optional: a TA can be inspected before tarunner is set up for it.
+	cfg, err := f.loadConfig(basedir)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+	dir, opts, err := f.prepare(basedir, logger)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+	report, err := collector.Inspect(dir, cfg, opts...)
+	if err != nil {
+		log.Printf("invalid configuration: %v", err)
+		return exitConfigError
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = printReport(os.Stdout, report)
+	}
+	if err != nil {
+		log.Print(err)
+		return exitRuntimeError
+	}
+
+	unsupported := report.Count(collector.Unsupported)
+	if *failOn == collector.Partial {
+		unsupported += report.Count(collector.Partial)
+	}
+	if *failOn != "none" && unsupported > 0 {
+		return exitUnsupported
+	}
+	return 0
+}
+
+// printReport writes a human-readable report, grouping stanzas per configuration file.
+func printReport(out io.Writer, report *collector.Report) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	file := ""
+	for _, s := range report.Stanzas {
+		if s.File != file {
+			file = s.File
+			_, _ = fmt.Fprintf(w, "\n%s (%s)\n", file, s.App)
+		}
+		details := s.Reason
+		if len(s.IgnoredKeys) > 0 {
+			if details != "" {
+				details += "; "
+			}
+			details += "ignored keys: " + strings.Join(s.IgnoredKeys, ", ")
+		}
+		if s.Disabled {
+			details = strings.TrimPrefix(details+"; disabled", "; ")
+		}
+		_, _ = fmt.Fprintf(w, "  [%s]\t%s\t%s\n", s.Stanza, s.Support, details)
+	}
+	_, _ = fmt.Fprintf(w, "\n%d supported, %d partially supported, %d unsupported\n",
+		report.Count(collector.Supported), report.Count(collector.Partial), report.Count(collector.Unsupported))
+	return w.Flush()
+}
@@ -4,46 +4,77 @@
 package main
 
 import (
-	"log"
+	"fmt"
 	"os"
-	"os/signal"
-	"path/filepath"
-	"syscall"
-
-	"github.com/splunk/tarunner/internal/config"
+)
 
-	"github.com/splunk/tarunner/internal/collector"
+const (
+	// exitRuntimeError is returned when the TA could not be started or failed while running.
+	exitRuntimeError = 1
+	// exitConfigError is returned when the command line, tarunner.yaml or the TA configuration is invalid.
+	exitConfigError = 2
+	// exitDataLoss is returned when data could not be delivered before the process stopped.
+	exitDataLoss = 3
+	// exitUnsupported is returned by inspect when a stanza is not supported at the level set by --fail-on.
+	exitUnsupported = 4
 )
 
+type command struct {
+	name        string
+	description string
+	run         func(args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "run", description: "Run a technical addon", run: runCommand},
+		{name: "run-input", description: "Run a single input of a technical addon", run: runInputCommand},
+		{name: "validate", description: "Validate the configuration of a technical addon without running it", run: validateCommand},
+		{name: "inspect", description: "Report which stanzas of a technical addon are supported", run: inspectCommand},
+		{name: "btool", description: "Print the configuration files of a technical addon as tarunner reads them", run: btoolCommand},
+		{name: "preview", description: "Run the props and transforms of a technical addon over a sample file", run: previewCommand},
+		{name: "ship", description: "Send the files written by a file exporter to an exporter", run: shipCommand},
+		{name: "encrypt", description: "Encrypt a secret with a splunk.secret file", run: encryptCommand},
+		{name: "version", description: "Print the version of tarunner", run: versionCommand},
+		{name: "help", description: "Print this help message", run: helpCommand},
+	}
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatalf("usage: %s <basedir>", os.Args[0])
+	os.Exit(dispatch(os.Args[1:]))
+}
+
+func dispatch(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitConfigError
 	}
-	basedir := os.Args[1]
-	configFile := filepath.Join(basedir, "tarunner.yaml")
-	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		log.Fatalf("config file %q does not exist", configFile)
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:])
+		}
 	}
-	cfg, err := config.LoadConfig(configFile)
-	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
+	switch args[0] {
+	case "-h", "-help", "--help":
+		usage()
+		return 0
 	}
+	// Backwards compatibility: `tarunner <basedir>` runs the TA.
+	return runCommand(args)
+}
 
-	shutdownFunc, err := collector.Run(basedir, cfg)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if shutdownFunc == nil {
-		return
-	}
+func helpCommand([]string) int {
+	usage()
+	return 0
+}
 
-	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, syscall.SIGTERM)
-	waitShutdown := make(chan struct{})
-	go func() {
-		<-signalChan
-		shutdownFunc()
-		close(waitShutdown)
-	}()
-	<-waitShutdown
+func usage() {
+	out := os.Stderr
+	_, _ = fmt.Fprintf(out, "usage: %s <command> [flags] <basedir>\n\nCommands:\n", os.Args[0])
+	for _, c := range commands {
+		_, _ = fmt.Fprintf(out, "  %-10s %s\n", c.name, c.description)
+	}
+	_, _ = fmt.Fprintf(out, "\nRun `%s <command> -h` to list the flags of a command.\n", os.Args[0])
 }
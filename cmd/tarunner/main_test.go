@@ -0,0 +1,109 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/tarunner/internal/config"
+)
+
+func TestExitCodes(t *testing.T) {
+	ta := filepath.Join("testdata", "ta")
+	secretPath := filepath.Join(t.TempDir(), "splunk.secret")
+	require.NoError(t, os.WriteFile(secretPath, []byte(strings.Repeat("s", 255)), 0o600))
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "no command", args: nil, want: exitConfigError},
+		{name: "help", args: []string{"help"}, want: 0},
+		{name: "help flag", args: []string{"--help"}, want: 0},
+		{name: "version", args: []string{"version"}, want: 0},
+
+		{name: "run help", args: []string{"run", "-h"}, want: 0},
+		{name: "run without basedir", args: []string{"run"}, want: exitConfigError},
+		{name: "run with unknown flag", args: []string{"run", "--unknown", ta}, want: exitConfigError},
+		{name: "run with invalid log level", args: []string{"run", "--log-level", "loud", ta}, want: exitConfigError},
+		{name: "run with max events without dry run", args: []string{"run", "--max-events", "1", ta}, want: exitConfigError},
+		{name: "run once with watch", args: []string{"run", "--once", "--watch", ta}, want: exitConfigError},
+		{name: "run with missing config", args: []string{"run", "--config", filepath.Join("testdata", "missing.yaml"), ta}, want: exitConfigError},
+		{name: "run with invalid config", args: []string{"run", "--config", filepath.Join("testdata", "invalid.yaml"), ta}, want: exitConfigError},
+		{name: "run without exporter", args: []string{"run", ta}, want: exitConfigError},
+		{name: "run with invalid pattern", args: []string{"run", "--only", "[", ta}, want: exitConfigError},
+		{name: "run with unmatched pattern", args: []string{"run", "--dry-run", "--only", "monitor://*", ta}, want: exitConfigError},
+		{name: "run once", args: []string{"run", "--dry-run", "--once", ta}, want: 0},
+		{name: "backwards compatible run", args: []string{"--dry-run", "--once", ta}, want: 0},
+
+		{name: "run-input help", args: []string{"run-input", "-h"}, want: 0},
+		{name: "run-input without stanza", args: []string{"run-input"}, want: exitConfigError},
+		{name: "run-input with too many arguments", args: []string{"run-input", "script://./bin/hello.sh", ta, ta}, want: exitConfigError},
+		{name: "run-input once with times", args: []string{"run-input", "--once", "--times", "2", "script://./bin/hello.sh", ta}, want: exitConfigError},
+		{name: "run-input with negative times", args: []string{"run-input", "--times", "-1", "script://./bin/hello.sh", ta}, want: exitConfigError},
+		{name: "run-input once of a monitor", args: []string{"run-input", "--once", "monitor:///var/log", ta}, want: exitConfigError},
+		{name: "run-input of an unknown stanza", args: []string{"run-input", "--dry-run", "--once", "script://./bin/missing.sh", ta}, want: exitConfigError},
+		{name: "run-input once", args: []string{"run-input", "--dry-run", "--once", "script://./bin/hello.sh", ta}, want: 0},
+		{name: "run-input with flags after the stanza", args: []string{"run-input", "script://./bin/hello.sh", "--dry-run", "--once", ta}, want: 0},
+		{name: "run-input of a failing script", args: []string{"run-input", "--dry-run", "--once", "script://./bin/fail.sh", ta}, want: 1},
+
+		{name: "validate help", args: []string{"validate", "-h"}, want: 0},
+		{name: "validate without exporter", args: []string{"validate", ta}, want: exitConfigError},
+		{name: "validate", args: []string{"validate", "--config", filepath.Join("testdata", "valid.yaml"), ta}, want: 0},
+		{name: "validate invalid config", args: []string{"validate", "--config", filepath.Join("testdata", "invalid.yaml"), ta}, want: exitConfigError},
+
+		{name: "btool help", args: []string{"btool", "-h"}, want: 0},
+		{name: "btool without list", args: []string{"btool", "inputs", ta}, want: exitConfigError},
+		{name: "btool with too many arguments", args: []string{"btool", "inputs", "list", "stanza", "other", ta}, want: exitConfigError},
+		{name: "btool", args: []string{"btool", "inputs", "list", ta}, want: 0},
+		{name: "btool with flags after arguments", args: []string{"btool", "inputs", "list", "script://./bin/hello.sh", ta, "--debug"}, want: 0},
+
+		{name: "preview help", args: []string{"preview", "-h"}, want: 0},
+		{name: "preview without sample", args: []string{"preview"}, want: exitConfigError},
+		{name: "preview with invalid format", args: []string{"preview", "--format", "xml", filepath.Join("testdata", "sample.log"), ta}, want: exitConfigError},
+
+		{name: "ship help", args: []string{"ship", "-h"}, want: 0},
+		{name: "ship without spool dir", args: []string{"ship"}, want: exitConfigError},
+		{name: "ship with missing config", args: []string{"ship", "--config", filepath.Join("testdata", "missing.yaml"), t.TempDir()}, want: exitConfigError},
+
+		{name: "encrypt help", args: []string{"encrypt", "-h"}, want: 0},
+		{name: "encrypt without secret", args: []string{"encrypt", "changeme"}, want: exitConfigError},
+		{name: "encrypt with missing secret", args: []string{"encrypt", "--splunk-secret", filepath.Join("testdata", "missing.secret"), "changeme"}, want: exitConfigError},
+		{name: "encrypt with too many values", args: []string{"encrypt", "--splunk-secret", secretPath, "a", "b"}, want: exitConfigError},
+		{name: "encrypt", args: []string{"encrypt", "--splunk-secret", secretPath, "changeme"}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dispatch(tt.args))
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	ta := filepath.Join("testdata", "ta")
+	// tarunner.yaml is optional unless --config is set.
+	cfg, err := (&commonFlags{}).loadConfig(ta)
+	require.NoError(t, err)
+	assert.Equal(t, &config.Config{}, cfg)
+
+	_, err = (&commonFlags{configFile: filepath.Join("testdata", "missing.yaml")}).loadConfig(ta)
+	require.ErrorContains(t, err, "missing.yaml\" does not exist")
+
+	_, err = (&commonFlags{configFile: filepath.Join("testdata", "invalid.yaml")}).loadConfig(ta)
+	require.ErrorContains(t, err, "failed to load config: app: unknown key")
+
+	cfg, err = (&commonFlags{splunkSecret: "splunk.secret"}).loadConfig(ta)
+	require.NoError(t, err)
+	assert.Equal(t, "splunk.secret", cfg.SplunkSecret)
+
+	cfg, err = (&commonFlags{configFile: filepath.Join("testdata", "valid.yaml")}).loadConfig(ta)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:4318", cfg.Endpoint)
+}
@@ -0,0 +1,241 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/featuregate"
+	"go.uber.org/zap"
+
+	"github.com/splunk/tarunner/internal/collector"
+	"github.com/splunk/tarunner/internal/conf"
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/featuregates"
+	"github.com/splunk/tarunner/internal/preview"
+)
+
+func previewCommand(args []string) int {
+	var f commonFlags
+	fs := newFlagSet("preview", &f)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "usage: %s preview [flags] <sample file> [basedir|archive]\n\nEach line of the sample file is an event. Use - to read events from stdin.\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	sourceType := fs.String("sourcetype", "", "sourcetype of the events")
+	source := fs.String("source", "", "source of the events (default the path of the sample file)")
+	host := fs.String("host", "", "host of the events")
+	app := fs.String("app", "", "TA whose props.conf and transforms.conf apply, when running several TAs")
+	explain := fs.Bool("explain", false, "show the props stanzas matching each event, the transforms applied, and the changes made at each step")
+	format := fs.String("format", "text", "output format: text or json")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return parseExitCode(err)
+	}
+	if len(positional) < 1 || len(positional) > 2 {
+		fs.Usage()
+		return exitConfigError
+	}
+	sample, basedir := positional[0], "."
+	if len(positional) == 2 {
+		basedir = positional[1]
+	}
+	if *format != "text" && *format != "json" {
+		log.Printf("invalid format %q", *format)
+		return exitConfigError
+	}
+	if *source == "" && sample != "-" {
+		*source = sample
+	}
+	logger, err := f.newLogger()
+	if err != nil {
+		log.Printf("invalid log level: %v", err)
+		return exitConfigError
+	}
+	// The preview shows what HF mode does to events.
+	if err = featuregate.GlobalRegistry().Set(featuregates.CookFeatureGate.ID(), true); err != nil {
+		log.Print(err)
+		return exitRuntimeError
+	}
+	cfg, err := f.loadConfig(basedir)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+	dir, opts, err := f.prepare(basedir, logger)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+	props, transforms, err := readPropsAndTransforms(dir, cfg, *app, opts)
+	if err != nil {
+		log.Printf("invalid configuration: %v", err)
+		return exitConfigError
+	}
+	// Errors of operators are reported with the steps of each event.
+	set := component.TelemetrySettings{Logger: zap.NewNop()}
+	pipeline, err := preview.New(set, props, transforms)
+	if err != nil {
+		log.Printf("invalid configuration: %v", err)
+		return exitConfigError
+	}
+
+	in := os.Stdin
+	if sample != "-" {
+		if in, err = os.Open(sample); err != nil {
+			log.Print(err)
+			return exitConfigError
+		}
+		defer func() {
+			_ = in.Close()
+		}()
+	}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		e := entry.New()
+		e.Body = scanner.Text()
+		e.Attributes = map[string]any{}
+		for name, value := range map[string]string{"sourcetype": *sourceType, "source": *source, "host": *host} {
+			if value != "" {
+				e.Attributes[name] = value
+			}
+		}
+		result, err := pipeline.Run(context.Background(), e)
+		if err != nil {
+			log.Print(err)
+			return exitRuntimeError
+		}
+		if !*explain {
+			result.Steps = nil
+		}
+		if *format == "json" {
+			err = json.NewEncoder(os.Stdout).Encode(result)
+		} else {
+			err = printResult(os.Stdout, n, result)
+		}
+		if err != nil {
+			log.Print(err)
+			return exitRuntimeError
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		log.Print(err)
+		return exitRuntimeError
+	}
+	return 0
+}
+
+// readPropsAndTransforms reads the props.conf and transforms.conf files of a TA.
+func readPropsAndTransforms(dir string, cfg *config.Config, app string, opts []collector.Option) ([]conf.Prop, []conf.Transform, error) {
+	var props []conf.Prop
+	var transforms []conf.Transform
+	for _, name := range []string{"props", "transforms"} {
+		files, err := collector.ReadConf(dir, cfg, name, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		var paths []string
+		for _, file := range files {
+			if app == "" || file.App == app {
+				paths = append(paths, file.Path)
+			}
+		}
+		if len(paths) > 1 {
+			return nil, nil, fmt.Errorf("several TAs have a %s.conf file, select one with --app", name)
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		b, err := os.ReadFile(paths[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		if name == "props" {
+			props, err = conf.ReadProps(b)
+		} else {
+			transforms, err = conf.ReadTransforms(b)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", paths[0], err)
+		}
+	}
+	return props, transforms, nil
+}
+
+// printResult writes the result of the preview of an event, with the steps that matched if they are explained.
+func printResult(out io.Writer, n int, result preview.Result) error {
+	_, _ = fmt.Fprintf(out, "event %d\n  input:  %s\n", n, formatEvent(result.Input))
+	prop := ""
+	for _, step := range result.Steps {
+		if step.Prop != prop {
+			prop = step.Prop
+			if step.Matched {
+				_, _ = fmt.Fprintf(out, "  [%s] matched %s\n", prop, step.Condition)
+			} else {
+				_, _ = fmt.Fprintf(out, "  [%s] not matched %s\n", prop, step.Condition)
+			}
+		}
+		if !step.Matched {
+			continue
+		}
+		switch {
+		case step.Transform != "":
+			fired := "regex did not match"
+			if step.RegexMatched != nil && *step.RegexMatched {
+				fired = "regex matched"
+			}
+			_, _ = fmt.Fprintf(out, "    %s = %s: %s\n", step.Class, step.Transform, fired)
+		case len(step.Changes) > 0 || step.Error != "":
+			_, _ = fmt.Fprintf(out, "    %s\n", step.Operator)
+		}
+		for _, c := range step.Changes {
+			_, _ = fmt.Fprintf(out, "      %s: %s -> %s\n", c.Field, formatValue(c.Before), formatValue(c.After))
+		}
+		if step.Error != "" {
+			_, _ = fmt.Fprintf(out, "      error: %s\n", step.Error)
+		}
+	}
+	_, err := fmt.Fprintf(out, "  output: %s\n", formatEvent(result.Output))
+	return err
+}
+
+func formatEvent(e preview.Event) string {
+	var sb strings.Builder
+	sb.WriteString("body=" + formatValue(e.Body))
+	keys := make([]string, 0, len(e.Attributes))
+	for k := range e.Attributes {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		sb.WriteString(" " + k + "=" + formatValue(e.Attributes[k]))
+	}
+	return sb.String()
+}
+
+func formatValue(v any) string {
+	if v == nil {
+		return "(none)"
+	}
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
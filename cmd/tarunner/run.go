@@ -0,0 +1,148 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/splunk/tarunner/internal/collector"
+	"github.com/splunk/tarunner/internal/watch"
+)
+
+func runCommand(args []string) int {
+	var f commonFlags
+	var selection selectionFlags
+	fs := newFlagSet("run", &f)
+	selection.register(fs)
+	watchFiles := fs.Bool("watch", false, "reload the configuration when tarunner.yaml or the TA configuration files change")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 30*time.Second, "how long to wait for running scripts to stop and for the exporter to drain its queue on shutdown")
+	dryRun := fs.Bool("dry-run", false, "print events to stdout instead of exporting them")
+	dryRunFormat := fs.String("dry-run-format", collector.ConsoleRaw, "format of the events printed with --dry-run: raw or json")
+	maxEvents := fs.Int("max-events", 0, "with --dry-run, stop after printing this number of events")
+	once := fs.Bool("once", false, "run each script once instead of on its interval, and stop once all scripts ran")
+	basedir, err := parse(fs, args)
+	if err != nil {
+		return parseExitCode(err)
+	}
+	logger, err := f.newLogger()
+	if err != nil {
+		log.Printf("invalid log level: %v", err)
+		return exitConfigError
+	}
+	cfg, err := f.loadConfig(basedir)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+	if *maxEvents != 0 && !*dryRun {
+		log.Print("--max-events requires --dry-run")
+		return exitConfigError
+	}
+	if *once && *watchFiles {
+		log.Print("--once cannot be used with --watch")
+		return exitConfigError
+	}
+	dir, opts, err := f.prepare(basedir, logger)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+	opts = append(opts, selection.option())
+	if *dryRun {
+		opts = append(opts, collector.WithConsole(os.Stdout, *dryRunFormat, *maxEvents))
+	}
+	if *once {
+		opts = append(opts, collector.WithOnce())
+	}
+	c, err := collector.Start(dir, cfg, opts...)
+	if errors.Is(err, collector.ErrInvalidConfig) {
+		log.Print(err)
+		return exitConfigError
+	}
+	if err != nil {
+		log.Print(err)
+		return exitRuntimeError
+	}
+	if c.Inputs() == 0 && !*watchFiles {
+		// No jobs to schedule. Exit.
+		return shutdown(c, *shutdownTimeout)
+	}
+
+	reload := func() {
+		cfg, err := f.loadConfig(basedir)
+		if err != nil {
+			logger.Error("Failed to reload configuration", zap.Error(err))
+			return
+		}
+		if err = c.Reload(cfg); err != nil {
+			logger.Error("Failed to reload configuration", zap.Error(err))
+			return
+		}
+		logger.Info("Configuration reloaded", zap.Int("inputs", c.Inputs()))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if *watchFiles {
+		paths := append(c.ConfigPaths(), f.configPath(basedir))
+		go func() {
+			if err := watch.Watch(ctx, logger, paths, time.Second, reload); err != nil {
+				logger.Error("Failed to watch configuration files", zap.Error(err))
+			}
+		}()
+	}
+
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+loop:
+	for {
+		select {
+		case sig := <-signalChan:
+			if sig == syscall.SIGHUP {
+				reload()
+				continue
+			}
+			logger.Info("Shutting down", zap.Stringer("signal", sig), zap.Duration("timeout", *shutdownTimeout))
+			break loop
+		case <-c.Done():
+			logger.Info("Run complete, shutting down", zap.Duration("timeout", *shutdownTimeout))
+			break loop
+		}
+	}
+	go func() {
+		// A second signal stops the process without waiting for the shutdown to complete.
+		for sig := range signalChan {
+			if sig != syscall.SIGHUP {
+				logger.Warn("Forced shutdown", zap.Stringer("signal", sig))
+				os.Exit(exitDataLoss)
+			}
+		}
+	}()
+	return shutdown(c, *shutdownTimeout)
+}
+
+// shutdown stops the collector, waiting at most timeout, and returns the exit code of the process.
+func shutdown(c *collector.Collector, timeout time.Duration) int {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := c.Shutdown(ctx)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, collector.ErrDataLoss):
+		log.Printf("shutdown completed with data loss: %v", err)
+		return exitDataLoss
+	default:
+		log.Printf("shutdown completed with errors: %v", err)
+		return exitRuntimeError
+	}
+}
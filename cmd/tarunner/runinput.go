@@ -0,0 +1,124 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/splunk/tarunner/internal/collector"
+)
+
+func runInputCommand(args []string) int {
+	var f commonFlags
+	fs := newFlagSet("run-input", &f)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "usage: %s run-input [flags] <stanza> [basedir|archive]\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	once := fs.Bool("once", false, "run the script once, and exit with its exit status")
+	times := fs.Int("times", 0, "run the script this number of times on its interval, and exit with the exit status of its last execution")
+	app := fs.String("app", "", "TA defining the input, when running several TAs")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 30*time.Second, "how long to wait for running scripts to stop and for the exporter to drain its queue on shutdown")
+	dryRun := fs.Bool("dry-run", false, "print events to stdout instead of exporting them")
+	dryRunFormat := fs.String("dry-run-format", collector.ConsoleRaw, "format of the events printed with --dry-run: raw or json")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return parseExitCode(err)
+	}
+	if len(positional) < 1 || len(positional) > 2 {
+		fs.Usage()
+		return exitConfigError
+	}
+	stanza, basedir := positional[0], "."
+	if len(positional) == 2 {
+		basedir = positional[1]
+	}
+	if *once {
+		if *times != 0 {
+			log.Print("--once cannot be used with --times")
+			return exitConfigError
+		}
+		*times = 1
+	}
+	if *times < 0 {
+		log.Print("--times must be positive")
+		return exitConfigError
+	}
+	if parsed, err := url.Parse(stanza); *times > 0 && (err != nil || (parsed.Scheme != "script" && parsed.Scheme != "")) {
+		log.Printf("--once and --times only apply to script inputs, not to %q", stanza)
+		return exitConfigError
+	}
+	logger, err := f.newLogger()
+	if err != nil {
+		log.Printf("invalid log level: %v", err)
+		return exitConfigError
+	}
+	cfg, err := f.loadConfig(basedir)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+	dir, opts, err := f.prepare(basedir, logger)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+	opts = append(opts, collector.WithInput(*app, stanza))
+	if *dryRun {
+		opts = append(opts, collector.WithConsole(os.Stdout, *dryRunFormat, 0))
+	}
+	if *times > 0 {
+		opts = append(opts, collector.WithTimes(*times))
+	}
+	c, err := collector.Start(dir, cfg, opts...)
+	if errors.Is(err, collector.ErrInvalidConfig) {
+		log.Print(err)
+		return exitConfigError
+	}
+	if err != nil {
+		log.Print(err)
+		return exitRuntimeError
+	}
+
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+	select {
+	case sig := <-signalChan:
+		logger.Info("Shutting down", zap.Stringer("signal", sig), zap.Duration("timeout", *shutdownTimeout))
+	case <-c.Done():
+		logger.Info("Run complete, shutting down", zap.Duration("timeout", *shutdownTimeout))
+	}
+	go func() {
+		// A second signal stops the process without waiting for the shutdown to complete.
+		<-signalChan
+		os.Exit(exitDataLoss)
+	}()
+	if code := shutdown(c, *shutdownTimeout); code != 0 {
+		return code
+	}
+	return scriptExitCode(c.ScriptErrors())
+}
+
+// scriptExitCode returns the exit status of a script given the error of its execution.
+func scriptExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	log.Printf("script failed: %v", err)
+	return exitRuntimeError
+}
@@ -0,0 +1,77 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/splunk/tarunner/internal/collector"
+	"github.com/splunk/tarunner/internal/config"
+)
+
+func shipCommand(args []string) int {
+	var f commonFlags
+	fs := flag.NewFlagSet("ship", flag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "usage: %s ship [flags] <spool-dir>\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&f.configFile, "config", "tarunner.yaml", "path to the tarunner configuration file declaring the exporter to ship with")
+	fs.StringVar(&f.logLevel, "log-level", "info", "log level: debug, info, warn or error")
+	fs.StringVar(&f.systemDir, "system-dir", "", "folder holding an outputs.conf file declaring outputs to ship with")
+	fs.StringVar(&f.splunkSecret, "splunk-secret", "", "path to the splunk.secret file decrypting $7$ and $1$ values (default the splunk_secret key of tarunner.yaml)")
+	exporterName := fs.String("exporter", "", "name of the exporter to ship with (default the only default exporter that does not write files)")
+	archiveDir := fs.String("archive-dir", "", "folder where shipped files are moved to instead of being deleted")
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitConfigError
+	}
+	spoolDir := fs.Arg(0)
+	logger, err := f.newLogger()
+	if err != nil {
+		log.Printf("invalid log level: %v", err)
+		return exitConfigError
+	}
+	cfg := &config.Config{}
+	if _, err = os.Stat(f.configFile); err == nil || f.systemDir == "" {
+		if cfg, err = config.LoadConfig(f.configFile); err != nil {
+			log.Printf("failed to load config: %v", err)
+			return exitConfigError
+		}
+	}
+	if f.splunkSecret != "" {
+		cfg.SplunkSecret = f.splunkSecret
+	}
+	opts := []collector.Option{collector.WithLogger(logger)}
+	if f.systemDir != "" {
+		opts = append(opts, collector.WithSystemDir(f.systemDir))
+	}
+
+	shipper, err := collector.NewShipper(cfg, *exporterName, opts...)
+	if err != nil {
+		log.Printf("invalid configuration: %v", err)
+		return exitConfigError
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	shipped, err := shipper.Ship(ctx, spoolDir, *archiveDir)
+	if err != nil {
+		logger.Error("Failed to ship spool files", zap.Int("shipped", shipped), zap.Error(err))
+		return exitRuntimeError
+	}
+	logger.Info("Shipped spool files", zap.Int("files", shipped))
+	return 0
+}
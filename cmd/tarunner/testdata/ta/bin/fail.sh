@@ -0,0 +1,4 @@
+#!/bin/bash
+
+echo failed
+exit 1
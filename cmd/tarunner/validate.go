@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/splunk/tarunner/internal/collector"
+	"github.com/splunk/tarunner/internal/config"
+)
+
+// dialTimeout bounds the time to connect to an endpoint checked by --check-endpoints.
+const dialTimeout = 5 * time.Second
+
+func validateCommand(args []string) int {
+	var f commonFlags
+	var selection selectionFlags
+	fs := newFlagSet("validate", &f)
+	selection.register(fs)
+	checkEndpoints := fs.Bool("check-endpoints", false, "check that the endpoints of the exporters accept connections")
+	basedir, err := parse(fs, args)
+	if err != nil {
+		return parseExitCode(err)
+	}
+	logger, err := f.newLogger()
+	if err != nil {
+		log.Printf("invalid log level: %v", err)
+		return exitConfigError
+	}
+	// All problems are reported at once: the TAs are validated even if tarunner.yaml is invalid.
+	var problems []error
+	cfg, err := f.loadConfig(basedir)
+	if err != nil {
+		problems = append(problems, config.Problems(errors.Unwrap(err))...)
+		if len(problems) == 0 {
+			problems = append(problems, err)
+		}
+	}
+	dir, opts, err := f.prepare(basedir, logger)
+	if err != nil {
+		log.Print(err)
+		return exitConfigError
+	}
+	opts = append(opts, selection.option())
+	if cfg == nil {
+		// Without exporters, events are not sent anywhere.
+		cfg = &config.Config{}
+		opts = append(opts, collector.WithConsole(io.Discard, collector.ConsoleRaw, 0))
+	}
+	if err = collector.Validate(dir, cfg, opts...); err != nil {
+		problems = append(problems, config.Problems(err)...)
+	}
+	if *checkEndpoints && len(problems) == 0 {
+		problems = append(problems, checkExporterEndpoints(cfg)...)
+	}
+	if len(problems) > 0 {
+		log.Printf("invalid configuration, %d problem(s):", len(problems))
+		for _, problem := range problems {
+			log.Printf("  %v", problem)
+		}
+		return exitConfigError
+	}
+	fmt.Println("configuration is valid")
+	return 0
+}
+
+// checkExporterEndpoints connects to the endpoints of the exporters, and returns a problem per endpoint refusing the connection.
+func checkExporterEndpoints(cfg *config.Config) []error {
+	exporters := cfg.NamedExporters()
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var problems []error
+	for _, name := range names {
+		for _, address := range exporters[name].Addresses() {
+			conn, err := net.DialTimeout("tcp", address, dialTimeout)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("exporter %q: %w", name, err))
+				continue
+			}
+			_ = conn.Close()
+		}
+	}
+	return problems
+}
@@ -0,0 +1,211 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package archive extracts TAs packaged as .tgz or .spl files, as distributed on Splunkbase.
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checksumFile is the file of the work directory recording the checksum of the extracted archive.
+const checksumFile = ".sha256"
+
+// IsArchive returns true if path names a packaged TA: a .tgz, .tar.gz or .spl file.
+func IsArchive(path string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range []string{".tgz", ".tar.gz", ".spl"} {
+		if strings.HasSuffix(lower, ext) {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Name returns the name of the archive file without its extension.
+func Name(path string) string {
+	name := filepath.Base(path)
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tgz", ".spl"} {
+		if strings.HasSuffix(lower, ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+// Extract extracts the TA packaged in path into workDir, and returns the folder of the TA.
+// The content of workDir is managed by Extract: the archive is extracted again, replacing
+// the previous content, only when its checksum differs from the one of the last extraction.
+func Extract(path string, workDir string) (string, error) {
+	sum, err := checksum(path)
+	if err != nil {
+		return "", err
+	}
+	if previous, err := os.ReadFile(filepath.Join(workDir, checksumFile)); err == nil && string(previous) == sum {
+		return taDir(workDir)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(workDir), 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(workDir), "."+filepath.Base(workDir)+"-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = os.RemoveAll(tmp)
+	}()
+	if err = extract(path, tmp); err != nil {
+		return "", fmt.Errorf("failed to extract %q: %w", path, err)
+	}
+	if err = os.WriteFile(filepath.Join(tmp, checksumFile), []byte(sum), 0o644); err != nil {
+		return "", err
+	}
+	if err = os.RemoveAll(workDir); err != nil {
+		return "", err
+	}
+	if err = os.Rename(tmp, workDir); err != nil {
+		return "", err
+	}
+	return taDir(workDir)
+}
+
+// taDir returns the folder of the TA extracted in workDir. Packaged TAs hold a single folder
+// named after the app; archives holding the default and local folders directly are also accepted.
+func taDir(workDir string) (string, error) {
+	entries, err := os.ReadDir(workDir)
+	if err != nil {
+		return "", err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.Name() == checksumFile {
+			continue
+		}
+		if !entry.IsDir() {
+			return workDir, nil
+		}
+		dirs = append(dirs, entry.Name())
+	}
+	if len(dirs) == 1 && dirs[0] != "default" && dirs[0] != "local" {
+		return filepath.Join(workDir, dirs[0]), nil
+	}
+	return workDir, nil
+}
+
+func checksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// extract writes the content of the archive under dir. The archive may be compressed with gzip.
+func extract(path string, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = gz.Close()
+		}()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := targetPath(dir, hdr.Name)
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = writeFile(target, tr, hdr.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if filepath.IsAbs(hdr.Linkname) {
+				return fmt.Errorf("%q links outside of the archive", hdr.Name)
+			}
+			if _, err = targetPath(dir, filepath.Join(filepath.Dir(hdr.Name), hdr.Linkname)); err != nil {
+				return fmt.Errorf("%q links outside of the archive", hdr.Name)
+			}
+			if err = os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err = os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		default:
+			// Other entries, like the extended headers written by macOS tar, carry no TA content.
+		}
+	}
+}
+
+// targetPath returns where the archive entry name is extracted under dir,
+// rejecting names that would escape dir.
+func targetPath(dir string, name string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid path %q in archive", name)
+	}
+	return filepath.Join(dir, cleaned), nil
+}
+
+func writeFile(path string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeArchive(t *testing.T, path string, files map[string]string) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0o755,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err = tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+}
+
+func TestIsArchive(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"ta.tgz", "ta.tar.gz", "ta.SPL"} {
+		path := filepath.Join(dir, name)
+		writeArchive(t, path, nil)
+		assert.True(t, IsArchive(path), name)
+	}
+	assert.False(t, IsArchive(dir))
+	assert.False(t, IsArchive(filepath.Join(dir, "missing.tgz")))
+	assert.Equal(t, "Splunk_TA_nix", Name("/opt/Splunk_TA_nix.tar.gz"))
+}
+
+func TestExtract(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ta.spl")
+	workDir := filepath.Join(dir, "work")
+	writeArchive(t, path, map[string]string{
+		"my_ta/default/inputs.conf": "[script://./bin/app.sh]\n",
+		"my_ta/bin/app.sh":          "#!/bin/sh\n",
+	})
+
+	taDir, err := Extract(path, workDir)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(workDir, "my_ta"), taDir)
+	info, err := os.Stat(filepath.Join(taDir, "bin", "app.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	// The archive is not extracted again while its checksum is unchanged.
+	marker := filepath.Join(taDir, "marker")
+	require.NoError(t, os.WriteFile(marker, nil, 0o600))
+	_, err = Extract(path, workDir)
+	require.NoError(t, err)
+	assert.FileExists(t, marker)
+
+	writeArchive(t, path, map[string]string{
+		"my_ta/default/inputs.conf": "[script://./bin/other.sh]\n",
+	})
+	taDir, err = Extract(path, workDir)
+	require.NoError(t, err)
+	assert.NoFileExists(t, marker)
+	assert.NoFileExists(t, filepath.Join(taDir, "bin", "app.sh"))
+	b, err := os.ReadFile(filepath.Join(taDir, "default", "inputs.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, "[script://./bin/other.sh]\n", string(b))
+}
+
+func TestExtractRejectsPathsOutsideOfTheArchive(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ta.tgz")
+	writeArchive(t, path, map[string]string{
+		"../escape.sh": "#!/bin/sh\n",
+	})
+	_, err := Extract(path, filepath.Join(dir, "work"))
+	require.ErrorContains(t, err, "invalid path")
+	assert.NoFileExists(t, filepath.Join(dir, "escape.sh"))
+	assert.NoDirExists(t, filepath.Join(dir, "work"))
+}
@@ -0,0 +1,69 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/splunk/tarunner/internal/config"
+)
+
+// findTAs returns the folders of the TAs to run:
+// - the apps listed in cfg, relative to baseDir, if any.
+// - baseDir itself if it is a TA.
+// - otherwise, every TA found directly under baseDir, like the apps folder of a Splunk instance.
+func findTAs(baseDir string, cfg *config.Config) ([]string, error) {
+	if len(cfg.Apps) > 0 {
+		dirs := make([]string, len(cfg.Apps))
+		for i, app := range cfg.Apps {
+			if !filepath.IsAbs(app) {
+				app = filepath.Join(baseDir, app)
+			}
+			if !isTA(app) {
+				return nil, fmt.Errorf("%q is not a TA: no default or local folder found", app)
+			}
+			dirs[i] = app
+		}
+		return dirs, nil
+	}
+	if isTA(baseDir) {
+		return []string{baseDir}, nil
+	}
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		dir := filepath.Join(baseDir, entry.Name())
+		if entry.IsDir() && isTA(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no TA found in %q", baseDir)
+	}
+	return dirs, nil
+}
+
+// isTA returns true if dir holds TA configuration, in a default or local folder.
+func isTA(dir string) bool {
+	for _, sub := range []string{"default", "local"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// appName returns the name of the TA located in dir, which is the name of its folder.
+func appName(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(abs), nil
+}
@@ -7,9 +7,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sync"
 
 	"go.opentelemetry.io/collector/exporter"
 
@@ -24,11 +28,6 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
-	"go.opentelemetry.io/otel/metric"
-	"go.opentelemetry.io/otel/metric/noop"
-	"go.opentelemetry.io/otel/trace"
-	nooptrace "go.opentelemetry.io/otel/trace/noop"
-	"go.uber.org/zap"
 
 	"github.com/splunk/tarunner/internal/conf"
 	"github.com/splunk/tarunner/internal/receiver/monitorreceiver"
@@ -39,90 +38,522 @@ import (
 // The function returns an error if the collector could not start.
 // The function returns a shutdown function handle if any work is scheduled,
 // or nil if the TA has no activity and is therefore safe to exit.
-func Run(baseDir string, cfg *config.Config) (func(), error) {
-	logger, err := zap.NewProduction()
+func Run(baseDir string, cfg *config.Config, opts ...Option) (func(context.Context) error, error) {
+	c, err := Start(baseDir, cfg, opts...)
 	if err != nil {
 		return nil, err
 	}
-	meterProvider := noop.NewMeterProvider()
-	tracerProvider := nooptrace.NewTracerProvider()
-	var e exporter.Logs
-	if cfg.Type == "otlp_http" {
-		e, err = newOtlpHttpExporter(logger, cfg.Endpoint)
-	} else {
-		e, err = newHECExporter(logger, cfg.Endpoint, cfg.Token)
+	if c.Inputs() == 0 {
+		// No jobs to schedule. Exit.
+		return nil, c.Shutdown(context.Background())
 	}
+	return c.Shutdown, nil
+}
+
+// ErrDataLoss is returned by Shutdown when log records could not be delivered.
+var ErrDataLoss = errors.New("data loss")
+
+// ErrInvalidConfig is wrapped by the errors of Start when the configuration of the TAs or tarunner.yaml is invalid.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// Collector runs one or more TAs: one receiver per enabled input of each TA, sending log records
+// to the exporters through a router.
+type Collector struct {
+	settings  *settings
+	router    *router
+	cfg       *config.Config
+	tas       map[string]*ta
+	receivers map[inputKey]inputReceiver
+	baseDir   string
+	host      host
+	mu        sync.Mutex
+}
+
+// inputKey identifies an input stanza across TAs.
+type inputKey struct {
+	app    string
+	stanza string
+}
+
+// inputReceiver is a receiver created for an input stanza.
+type inputReceiver struct {
+	receiver receiver.Logs
+	input    conf.Input
+}
+
+// ta holds the configuration files of a TA.
+type ta struct {
+	name string
+	dir  string
+	// localDir is the folder holding the local configuration files of the TA.
+	localDir   string
+	inputs     []conf.Input
+	transforms []conf.Transform
+	props      []conf.Prop
+}
+
+// Start starts the collector with a baseDir working directory.
+// baseDir is either a TA, or a folder containing TAs. See findTAs.
+// The function returns an error if the collector could not start, wrapping ErrInvalidConfig if the configuration is invalid.
+func Start(baseDir string, cfg *config.Config, opts ...Option) (*Collector, error) {
+	s, err := newSettings(append([]Option{withTelemetryInterval(telemetryInterval)}, opts...))
 	if err != nil {
 		return nil, err
 	}
-	inputs, err := readInputs(baseDir)
+	cfg, r, tas, receivers, err := build(baseDir, cfg, s)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
 	}
-	transforms, err := readTransforms(baseDir)
+	h, err := newHost(cfg, s)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	c := &Collector{
+		settings:  s,
+		router:    r,
+		cfg:       cfg,
+		tas:       tas,
+		receivers: map[inputKey]inputReceiver{},
+		baseDir:   baseDir,
+		host:      h,
+	}
+
+	for id, ext := range h.extensions {
+		if err = ext.Start(context.Background(), c.host); err != nil {
+			_ = c.Shutdown(context.Background())
+			return nil, fmt.Errorf("failed to start extension %q: %w", id, err)
+		}
+	}
+	if _, ok := h.extensions[storageID]; ok {
+		if err = registerDiskUsage(s.meterProvider.Meter(meterName), cfg.Storage.Directory); err != nil {
+			_ = c.Shutdown(context.Background())
+			return nil, err
+		}
+	}
+	for _, name := range r.names() {
+		if err = r.exporters[name].Start(context.Background(), c.host); err != nil {
+			_ = c.Shutdown(context.Background())
+			return nil, fmt.Errorf("failed to start exporter %q: %w", name, err)
+		}
+	}
+	for _, r := range receivers {
+		if err = r.receiver.Start(context.Background(), c.host); err != nil {
+			_ = c.Shutdown(context.Background())
+			return nil, fmt.Errorf("failed to start receiver %q of %q: %w", r.input.Configuration.Stanza.Name, r.input.Configuration.Stanza.App, err)
+		}
+		c.receivers[r.key()] = r
+	}
+	if s.times > 0 {
+		go func() {
+			s.passes.Wait()
+			s.complete()
+		}()
+	}
+	return c, nil
+}
+
+// Done returns a channel closed when the run is complete: when all scripts ran their number of times,
+// or when the console exporter printed its maximum number of events.
+func (c *Collector) Done() <-chan struct{} {
+	return c.settings.done
+}
+
+// ScriptErrors returns the errors of the last execution of the scripts that ran their number of times,
+// such as *exec.ExitError if a script exited with a non-zero status.
+func (c *Collector) ScriptErrors() error {
+	c.settings.scriptErrsMu.Lock()
+	defer c.settings.scriptErrsMu.Unlock()
+	return errors.Join(c.settings.scriptErrs...)
+}
+
+func (r inputReceiver) key() inputKey {
+	return inputKey{app: r.input.Configuration.Stanza.App, stanza: r.input.Configuration.Stanza.Name}
+}
+
+// Inputs returns the number of inputs currently running.
+func (c *Collector) Inputs() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.receivers)
+}
+
+// ConfigPaths returns the directories holding the configuration files of the TAs.
+func (c *Collector) ConfigPaths() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var paths []string
+	if !isTA(c.baseDir) {
+		// TAs may be added to the folder.
+		paths = append(paths, c.baseDir)
 	}
-	props, err := readProps(baseDir)
+	for _, t := range c.tas {
+		paths = append(paths, filepath.Join(t.dir, "default"), t.localDir)
+	}
+	if c.settings.systemDir != "" {
+		paths = append(paths, c.settings.systemDir)
+	}
+	return paths
+}
+
+// Shutdown stops all receivers, letting running scripts stop and in-flight data reach the exporter,
+// then stops the exporter, draining its queue. Shutdown gives up waiting when ctx is done.
+// The function returns all errors met while shutting down, wrapping ErrDataLoss if the exporter
+// failed to deliver log records or could not drain its queue in time.
+func (c *Collector) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	var errsMu sync.Mutex
+	var wg sync.WaitGroup
+	for name, r := range c.receivers {
+		wg.Go(func() {
+			if err := shutdownComponent(ctx, r.receiver); err != nil {
+				errsMu.Lock()
+				errs = append(errs, fmt.Errorf("failed to stop receiver %q of %q: %w", name.stanza, name.app, err))
+				errsMu.Unlock()
+			}
+		})
+		delete(c.receivers, name)
+	}
+	wg.Wait()
+
+	for _, name := range c.router.names() {
+		wg.Go(func() {
+			err := shutdownComponent(ctx, c.router.exporters[name])
+			errsMu.Lock()
+			defer errsMu.Unlock()
+			if err != nil && ctx.Err() != nil {
+				errs = append(errs, fmt.Errorf("%w: the queue of exporter %q was not drained before the shutdown deadline", ErrDataLoss, name))
+			} else if err != nil {
+				errs = append(errs, fmt.Errorf("failed to stop exporter %q: %w", name, err))
+			}
+		})
+	}
+	wg.Wait()
+
+	// Persistent queues are closed by their exporter, which must be stopped first.
+	for id, ext := range c.host.extensions {
+		if err := ext.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop extension %q: %w", id, err))
+		}
+	}
+
+	dropped, err := c.settings.droppedLogRecords(context.Background())
 	if err != nil {
-		return nil, err
+		errs = append(errs, fmt.Errorf("failed to read exporter metrics: %w", err))
+	} else if dropped > 0 {
+		errs = append(errs, fmt.Errorf("%w: %d log records could not be exported", ErrDataLoss, dropped))
+	}
+	_ = c.settings.meterProvider.Shutdown(context.Background())
+
+	return errors.Join(errs...)
+}
+
+// shutdownComponent shuts down a component, returning early with the context error if ctx is done first.
+func shutdownComponent(ctx context.Context, c component.Component) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Shutdown(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Validate reads the TA located in baseDir and builds the exporter and receivers
+// described by cfg and the TA configuration files, without starting them.
+// The function returns an error if the collector could not be built.
+func Validate(baseDir string, cfg *config.Config, opts ...Option) error {
+	s, err := newSettings(opts)
+	if err != nil {
+		return err
+	}
+	if cfg, _, _, _, err = build(baseDir, cfg, s); err != nil {
+		return err
 	}
+	_, err = newHost(cfg, s)
+	return err
+}
 
-	receivers, err := createReceivers(inputs, transforms, props, baseDir, e, logger, meterProvider, tracerProvider)
+// build reads the TAs located in baseDir, and creates their receivers and the router sending their log records to the exporters.
+// The function returns cfg completed with the exporters declared by outputs.conf, with their secrets decrypted.
+func build(baseDir string, cfg *config.Config, s *settings) (*config.Config, *router, map[string]*ta, []inputReceiver, error) {
+	tas, err := readTAs(baseDir, cfg, s.localDir)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	outputs, err := readOutputs(tas, s.systemDir)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if cfg, err = withOutputs(cfg, outputs, s.logger); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if cfg, err = decryptSecrets(cfg); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	r, err := buildRouter(cfg, s)
 	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	if s.input != nil {
+		if err = selectInput(tas, *s.input); err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
+	if err = selectInputs(tas, s.only, s.exclude); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	s.offsets = offsetStorage(cfg, s)
+	var receivers []inputReceiver
+	for _, t := range tas {
+		created, err := createReceivers(t.inputs, t.transforms, t.props, cfg.Metadata, t.dir, r, s)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("%s: %w", t.name, err)
+		}
+		receivers = append(receivers, created...)
+	}
+	return cfg, r, tas, receivers, nil
+}
+
+// buildRouter creates the exporters declared by cfg, and the router sending log records to them.
+// The console exporter replaces all exporters if it is set.
+func buildRouter(cfg *config.Config, s *settings) (*router, error) {
+	if s.console != nil {
+		e, err := newConsoleExporter(*s.console, s.complete)
+		if err != nil {
+			return nil, err
+		}
+		return newRouter(map[string]exporter.Logs{"console": e}, nil, []string{"console"}), nil
+	}
+	if cfg.Type == "" && len(cfg.Exporters) == 0 {
+		return nil, errors.New("no exporter is configured: declare one in tarunner.yaml, or outputs in outputs.conf")
+	}
+	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
+	exporters := map[string]exporter.Logs{}
+	storage := newQueueStorage(cfg)
+	for name, eCfg := range cfg.NamedExporters() {
+		e, err := newExporter(s.telemetrySettings(), name, eCfg, storage)
+		if err != nil {
+			return nil, fmt.Errorf("exporter %q: %w", name, err)
+		}
+		exporters[name] = e
+	}
+	return newRouter(exporters, cfg.Routes, cfg.DefaultExporters()), nil
+}
 
-	if len(receivers) == 0 {
-		// No jobs to schedule. Exit.
-		return nil, nil
+// selectInput removes all inputs of tas but the input identified by key, and enables it.
+// The function returns an error unless exactly one input matches key.
+func selectInput(tas map[string]*ta, key inputKey) error {
+	var selected *ta
+	for _, t := range tas {
+		if key.app != "" && key.app != t.name {
+			t.inputs = nil
+			continue
+		}
+		var inputs []conf.Input
+		for _, input := range t.inputs {
+			if input.Configuration.Stanza.Name == key.stanza {
+				inputs = append(inputs, enable(input))
+			}
+		}
+		t.inputs = inputs
+		if len(inputs) == 0 {
+			continue
+		}
+		if selected != nil {
+			return fmt.Errorf("input %q is defined by TAs %q and %q, select one", key.stanza, selected.name, t.name)
+		}
+		selected = t
 	}
+	if selected == nil {
+		return fmt.Errorf("input %q not found", key.stanza)
+	}
+	return nil
+}
 
-	h := host{}
+// selectInputs only keeps the inputs whose stanza name matches one of the only patterns, if any,
+// and none of the exclude patterns. The function returns an error if an only pattern matches no input.
+func selectInputs(tas map[string]*ta, only []string, exclude []string) error {
+	if len(only) == 0 && len(exclude) == 0 {
+		return nil
+	}
+	matched := make([]bool, len(only))
+	for _, t := range tas {
+		var inputs []conf.Input
+		for _, input := range t.inputs {
+			name := input.Configuration.Stanza.Name
+			selected := len(only) == 0
+			for i, pattern := range only {
+				if ok, err := path.Match(pattern, name); err != nil {
+					return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+				} else if ok {
+					selected = true
+					matched[i] = true
+				}
+			}
+			for _, pattern := range exclude {
+				if ok, err := path.Match(pattern, name); err != nil {
+					return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+				} else if ok {
+					selected = false
+				}
+			}
+			if selected {
+				inputs = append(inputs, input)
+			}
+		}
+		t.inputs = inputs
+	}
+	for i, pattern := range only {
+		if !matched[i] {
+			return fmt.Errorf("no input matches %q", pattern)
+		}
+	}
+	return nil
+}
 
-	err = e.Start(context.Background(), h)
+// enable returns the input without its disabled key.
+func enable(input conf.Input) conf.Input {
+	var params conf.Params
+	for _, p := range input.Configuration.Stanza.Params {
+		if p.Name != "disabled" {
+			params = append(params, p)
+		}
+	}
+	input.Configuration.Stanza.Params = params
+	return input
+}
+
+// readTAs reads the configuration files of all the TAs to run.
+// If localDir is set, it replaces the local folder of the TA, and only one TA may be run.
+func readTAs(baseDir string, cfg *config.Config, localDir string) (map[string]*ta, error) {
+	dirs, err := findTAs(baseDir, cfg)
 	if err != nil {
 		return nil, err
 	}
-	for _, l := range receivers {
-		if err = l.Start(context.Background(), h); err != nil {
-			return nil, err
+	if localDir != "" && len(dirs) > 1 {
+		return nil, fmt.Errorf("a local folder can only be set when running a single TA, found %d TAs", len(dirs))
+	}
+	tas := make(map[string]*ta, len(dirs))
+	for _, dir := range dirs {
+		t, err := readTA(dir, taLocalDir(dir, localDir))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
 		}
+		if other, ok := tas[t.name]; ok {
+			return nil, fmt.Errorf("TAs %q and %q have the same name %q", other.dir, t.dir, t.name)
+		}
+		overrideInputs(t.inputs, cfg)
+		tas[t.name] = t
 	}
+	return tas, nil
+}
 
-	shutDownFunc := func() {
-		for _, l := range receivers {
-			_ = l.Shutdown(context.Background())
+// overrideInputs sets the params of the inputs to the values of the inputs section of cfg, see config.Config.InputParams.
+func overrideInputs(inputs []conf.Input, cfg *config.Config) {
+	for i, input := range inputs {
+		overrides := cfg.InputParams(input.Configuration.Stanza.Name)
+		if len(overrides) == 0 {
+			continue
+		}
+		var params conf.Params
+		for _, p := range input.Configuration.Stanza.Params {
+			if value, ok := overrides[p.Name]; ok {
+				p.Value = value
+				delete(overrides, p.Name)
+			}
+			params = append(params, p)
 		}
-		_ = e.Shutdown(context.Background())
+		for _, name := range slices.Sorted(maps.Keys(overrides)) {
+			params = append(params, conf.Param{Name: name, Value: overrides[name]})
+		}
+		inputs[i].Configuration.Stanza.Params = params
 	}
+}
 
-	return shutDownFunc, nil
+// taLocalDir returns the folder holding the local configuration files of the TA located in dir.
+func taLocalDir(dir string, localDir string) string {
+	if localDir != "" {
+		return localDir
+	}
+	return filepath.Join(dir, "local")
 }
 
-func createReceivers(inputs []conf.Input, transforms []conf.Transform, props []conf.Prop, baseDir string, next consumer.Logs, logger *zap.Logger, meterProvider metric.MeterProvider, tracerProvider trace.TracerProvider) ([]receiver.Logs, error) {
-	var receivers []receiver.Logs
+func readTA(baseDir string, localDir string) (*ta, error) {
+	name, err := appName(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := readInputs(baseDir, localDir)
+	if err != nil {
+		return nil, err
+	}
+	for i := range inputs {
+		inputs[i].Configuration.Stanza.App = name
+	}
+	transforms, err := readTransforms(baseDir, localDir)
+	if err != nil {
+		return nil, err
+	}
+	props, err := readProps(baseDir, localDir)
+	if err != nil {
+		return nil, err
+	}
+	return &ta{
+		name:       name,
+		dir:        baseDir,
+		localDir:   localDir,
+		inputs:     inputs,
+		transforms: transforms,
+		props:      props,
+	}, nil
+}
+
+func createReceivers(inputs []conf.Input, transforms []conf.Transform, props []conf.Prop, metadata config.Metadata, baseDir string, r *router, s *settings) ([]inputReceiver, error) {
+	var receivers []inputReceiver
 	for _, input := range inputs {
-		disabled := input.Configuration.Stanza.Params.Get("disabled")
-		if disabled != nil && disabled.Value == "1" {
+		if isDisabled(input) {
 			continue
 		}
-		l, err := createReceiver(baseDir, next, input, transforms, props, logger, meterProvider, tracerProvider)
+		l, err := createReceiver(baseDir, r.forConfiguredInput(input, s.logger), input, transforms, props, metadata, s)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create receiver %q: %w", input.Configuration.Stanza.Name, err)
 		}
-		receivers = append(receivers, l)
+		receivers = append(receivers, inputReceiver{receiver: l, input: input})
 	}
 	return receivers, nil
 }
 
-func readInputs(baseDir string) ([]conf.Input, error) {
-	fileToRead := filepath.Join(baseDir, "local", "inputs.conf")
-	if _, err := os.Stat(fileToRead); errors.Is(err, os.ErrNotExist) {
-		fileToRead = filepath.Join(baseDir, "default", "inputs.conf")
-		if _, err := os.Stat(fileToRead); errors.Is(err, os.ErrNotExist) {
-			return nil, err
-		}
+func isDisabled(input conf.Input) bool {
+	disabled := input.Configuration.Stanza.Params.Get("disabled")
+	return disabled != nil && disabled.Value == "1"
+}
+
+// confPath returns the path of a configuration file of a TA: the file of the local folder if it exists,
+// or else the file of the default folder. The function returns an error wrapping os.ErrNotExist if neither exists.
+func confPath(baseDir string, localDir string, name string) (string, error) {
+	path := filepath.Join(localDir, name)
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return path, err
+	}
+	path = filepath.Join(baseDir, "default", name)
+	_, err := os.Stat(path)
+	return path, err
+}
+
+func readInputs(baseDir string, localDir string) ([]conf.Input, error) {
+	fileToRead, err := confPath(baseDir, localDir, "inputs.conf")
+	if err != nil {
+		return nil, err
 	}
 	b, err := os.ReadFile(fileToRead)
 	if err != nil {
@@ -131,13 +562,13 @@ func readInputs(baseDir string) ([]conf.Input, error) {
 	return conf.ReadInput(b)
 }
 
-func readTransforms(baseDir string) ([]conf.Transform, error) {
-	fileToRead := filepath.Join(baseDir, "local", "transforms.conf")
-	if _, err := os.Stat(fileToRead); errors.Is(err, os.ErrNotExist) {
-		fileToRead = filepath.Join(baseDir, "default", "transforms.conf")
-		if _, err := os.Stat(fileToRead); errors.Is(err, os.ErrNotExist) {
-			return nil, nil
-		}
+func readTransforms(baseDir string, localDir string) ([]conf.Transform, error) {
+	fileToRead, err := confPath(baseDir, localDir, "transforms.conf")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 	b, err := os.ReadFile(fileToRead)
 	if err != nil {
@@ -146,13 +577,13 @@ func readTransforms(baseDir string) ([]conf.Transform, error) {
 	return conf.ReadTransforms(b)
 }
 
-func readProps(baseDir string) ([]conf.Prop, error) {
-	fileToRead := filepath.Join(baseDir, "local", "props.conf")
-	if _, err := os.Stat(fileToRead); errors.Is(err, os.ErrNotExist) {
-		fileToRead = filepath.Join(baseDir, "default", "props.conf")
-		if _, err := os.Stat(fileToRead); errors.Is(err, os.ErrNotExist) {
-			return nil, nil
-		}
+func readProps(baseDir string, localDir string) ([]conf.Prop, error) {
+	fileToRead, err := confPath(baseDir, localDir, "props.conf")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 	b, err := os.ReadFile(fileToRead)
 	if err != nil {
@@ -161,7 +592,7 @@ func readProps(baseDir string) ([]conf.Prop, error) {
 	return conf.ReadProps(b)
 }
 
-func createReceiver(baseDir string, next consumer.Logs, input conf.Input, transforms []conf.Transform, props []conf.Prop, logger *zap.Logger, meterProvider metric.MeterProvider, tracerProvider trace.TracerProvider) (receiver.Logs, error) {
+func createReceiver(baseDir string, next consumer.Logs, input conf.Input, transforms []conf.Transform, props []conf.Prop, metadata config.Metadata, s *settings) (receiver.Logs, error) {
 	parsed, err := url.Parse(input.Configuration.Stanza.Name)
 	if err != nil {
 		return nil, err
@@ -170,85 +601,73 @@ func createReceiver(baseDir string, next consumer.Logs, input conf.Input, transf
 	case "script", "":
 		f := scriptreceiver.NewFactory()
 		l, err := f.CreateLogs(context.Background(), receiver.Settings{
-			ID: component.MustNewIDWithName(f.Type().String(), parsed.Path),
-			TelemetrySettings: component.TelemetrySettings{
-				Logger:         logger,
-				MeterProvider:  meterProvider,
-				TracerProvider: tracerProvider,
-			},
+			ID:                component.MustNewIDWithName(f.Type().String(), parsed.Path),
+			TelemetrySettings: s.telemetrySettings(),
 		}, &scriptreceiver.Config{
 			Input:      input,
 			BaseDir:    baseDir,
 			Transforms: transforms,
 			Props:      props,
+			Times:      s.times,
+			Ran:        s.scriptRan(),
+			Metadata:   metadata,
 		},
 			next)
 		return l, err
 	case "monitor":
 		f := monitorreceiver.NewFactory()
 		l, err := f.CreateLogs(context.Background(), receiver.Settings{
-			ID: component.MustNewIDWithName(f.Type().String(), parsed.Path),
-			TelemetrySettings: component.TelemetrySettings{
-				Logger:         logger,
-				MeterProvider:  meterProvider,
-				TracerProvider: tracerProvider,
-			},
+			ID:                component.MustNewIDWithName(f.Type().String(), parsed.Path),
+			TelemetrySettings: s.telemetrySettings(),
 		}, monitorreceiver.Config{
 			Input:      input,
 			BaseDir:    baseDir,
 			Transforms: transforms,
 			Props:      props,
+			Metadata:   metadata,
+			StorageID:  s.offsets,
 		},
 			next)
 		return l, err
 	case "WinEventLog":
 		f := wineventlogreceiver.NewFactory()
 		l, err := f.CreateLogs(context.Background(), receiver.Settings{
-			ID: component.MustNewIDWithName(f.Type().String(), parsed.Path),
-			TelemetrySettings: component.TelemetrySettings{
-				Logger:         logger,
-				MeterProvider:  meterProvider,
-				TracerProvider: tracerProvider,
-			},
+			ID:                component.MustNewIDWithName(f.Type().String(), parsed.Path),
+			TelemetrySettings: s.telemetrySettings(),
 		}, wineventlogreceiver.Config{
 			Input:      input,
 			BaseDir:    baseDir,
 			Transforms: transforms,
 			Props:      props,
+			Metadata:   metadata,
 		},
 			next)
 		return l, err
 	case "tcp":
 		f := tcpreceiver.NewFactory()
 		l, err := f.CreateLogs(context.Background(), receiver.Settings{
-			ID: component.MustNewIDWithName(f.Type().String(), parsed.Path),
-			TelemetrySettings: component.TelemetrySettings{
-				Logger:         logger,
-				MeterProvider:  meterProvider,
-				TracerProvider: tracerProvider,
-			},
+			ID:                component.MustNewIDWithName(f.Type().String(), parsed.Path),
+			TelemetrySettings: s.telemetrySettings(),
 		}, tcpreceiver.Config{
 			Input:      input,
 			BaseDir:    baseDir,
 			Transforms: transforms,
 			Props:      props,
+			Metadata:   metadata,
 		},
 			next)
 		return l, err
 	case "udp":
 		f := udpreceiver.NewFactory()
 		l, err := f.CreateLogs(context.Background(), receiver.Settings{
-			ID: component.MustNewIDWithName(f.Type().String(), parsed.Path),
-			TelemetrySettings: component.TelemetrySettings{
-				Logger:         logger,
-				MeterProvider:  meterProvider,
-				TracerProvider: tracerProvider,
-			},
+			ID:                component.MustNewIDWithName(f.Type().String(), parsed.Path),
+			TelemetrySettings: s.telemetrySettings(),
 		}, udpreceiver.Config{
 			Input:      input,
 			BaseDir:    baseDir,
 			Transforms: transforms,
 			Props:      props,
+			Metadata:   metadata,
 		},
 			next)
 		return l, err
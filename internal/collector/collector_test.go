@@ -6,22 +6,38 @@
 package collector
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"net"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/ackextension"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/splunkhecreceiver"
 
 	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/exporter/s2sexporter/s2stest"
+	"github.com/splunk/tarunner/internal/featuregates"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configopaque"
 	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/featuregate"
 	"go.opentelemetry.io/collector/receiver/otlpreceiver"
 	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestRunTA(t *testing.T) {
@@ -38,15 +54,81 @@ func TestRunTA(t *testing.T) {
 		_ = rcvr.Shutdown(context.Background())
 	}()
 	cancel, err := Run(filepath.Join("testdata", "ta"), &config.Config{
-		Type:     "otlp_http",
-		Endpoint: "http://localhost:1337",
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1337",
+		},
 	})
 	require.NoError(t, err)
-	defer cancel()
+	defer func() {
+		assert.NoError(t, cancel(context.Background()))
+	}()
+
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		assert.Greater(tt, logsSink.LogRecordCount(), 0)
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestRunTAWithOtlpGrpc(t *testing.T) {
+	logsSink := &consumertest.LogsSink{}
+	cfg := otlpreceiver.NewFactory().CreateDefaultConfig().(*otlpreceiver.Config)
+	grpc := cfg.GRPC.GetOrInsertDefault()
+	grpc.NetAddr.Endpoint = "localhost:1348"
+	grpc.IncludeMetadata = true
+
+	rcvr, err := otlpreceiver.NewFactory().CreateLogs(context.Background(), receivertest.NewNopSettings(otlpreceiver.NewFactory().Type()), cfg, logsSink)
+	require.NoError(t, err)
+	err = rcvr.Start(context.Background(), componenttest.NewNopHost())
+	require.NoError(t, err)
+	defer func() {
+		_ = rcvr.Shutdown(context.Background())
+	}()
+	headers := configopaque.MapList{}
+	headers.Set("X-Tenant", "security")
+	cancel, err := Run(filepath.Join("testdata", "ta"), &config.Config{
+		Exporter: config.Exporter{
+			Type:        "otlp_grpc",
+			Endpoint:    "http://localhost:1348",
+			Headers:     headers,
+			Compression: "zstd",
+		},
+	})
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cancel(context.Background()))
+	}()
 
 	require.EventuallyWithT(t, func(tt *assert.CollectT) {
 		assert.Greater(tt, logsSink.LogRecordCount(), 0)
 	}, 2*time.Second, 10*time.Millisecond)
+	info := client.FromContext(logsSink.Contexts()[0])
+	assert.Equal(t, []string{"security"}, info.Metadata.Get("X-Tenant"))
+}
+
+func TestRunTAWithS2S(t *testing.T) {
+	s, err := s2stest.NewServer()
+	require.NoError(t, err)
+	defer func() {
+		_ = s.Close()
+	}()
+	cancel, err := Run(filepath.Join("testdata", "ta"), &config.Config{
+		Exporter: config.Exporter{
+			Type:    "splunk_s2s",
+			Servers: []string{s.Addr()},
+			UseACK:  true,
+		},
+	})
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cancel(context.Background()))
+	}()
+
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		events := s.Events()
+		if assert.NotEmpty(tt, events) {
+			assert.Equal(tt, "sourcetype::_foo", events[0]["MetaData:Sourcetype"])
+		}
+	}, 2*time.Second, 10*time.Millisecond)
 }
 
 func TestRunPeriodic(t *testing.T) {
@@ -61,11 +143,15 @@ func TestRunPeriodic(t *testing.T) {
 		_ = rcvr.Shutdown(context.Background())
 	}()
 	cancel, err := Run(filepath.Join("testdata", "periodic"), &config.Config{
-		Type:     "otlp_http",
-		Endpoint: "http://localhost:1338",
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1338",
+		},
 	})
 	require.NoError(t, err)
-	defer cancel()
+	defer func() {
+		assert.NoError(t, cancel(context.Background()))
+	}()
 
 	require.EventuallyWithT(t, func(tt *assert.CollectT) {
 		assert.Equal(tt, 1, logsSink.LogRecordCount())
@@ -102,8 +188,10 @@ func TestRunDisabled(t *testing.T) {
 		_ = rcvr.Shutdown(context.Background())
 	}()
 	cancel, err := Run(filepath.Join("testdata", "disabled"), &config.Config{
-		Type:     "otlp_http",
-		Endpoint: "http://localhost:1339",
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1339",
+		},
 	})
 	require.NoError(t, err)
 	require.Nil(t, cancel)
@@ -124,11 +212,15 @@ func TestRunDisabledInterval(t *testing.T) {
 		_ = rcvr.Shutdown(context.Background())
 	}()
 	cancel, err := Run(filepath.Join("testdata", "disabled_interval"), &config.Config{
-		Type:     "otlp_http",
-		Endpoint: "http://localhost:1340",
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1340",
+		},
 	})
 	require.NoError(t, err)
-	defer cancel()
+	defer func() {
+		assert.NoError(t, cancel(context.Background()))
+	}()
 
 	assert.Equal(t, 0, logsSink.LogRecordCount())
 }
@@ -145,11 +237,15 @@ func TestRunScriptedInputs(t *testing.T) {
 		_ = rcvr.Shutdown(context.Background())
 	}()
 	cancel, err := Run(filepath.Join("testdata", "script"), &config.Config{
-		Type:     "otlp_http",
-		Endpoint: "http://localhost:1341",
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1341",
+		},
 	})
 	require.NoError(t, err)
-	defer cancel()
+	defer func() {
+		assert.NoError(t, cancel(context.Background()))
+	}()
 
 	require.EventuallyWithT(t, func(tt *assert.CollectT) {
 		assert.GreaterOrEqual(tt, logsSink.LogRecordCount(), 1)
@@ -186,7 +282,7 @@ func TestReadTransforms(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			transforms, err := readTransforms(test.path)
+			transforms, err := readTransforms(test.path, filepath.Join(test.path, "local"))
 			require.NoError(t, err)
 			require.Len(t, transforms, 1)
 			require.Equal(t, test.expectedName, transforms[0].Name)
@@ -208,11 +304,15 @@ func TestUseTCP(t *testing.T) {
 		_ = rcvr.Shutdown(context.Background())
 	}()
 	cancel, err := Run(rootDir, &config.Config{
-		Type:     "otlp_http",
-		Endpoint: "http://localhost:1342",
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1342",
+		},
 	})
 	require.NoError(t, err)
-	defer cancel()
+	defer func() {
+		assert.NoError(t, cancel(context.Background()))
+	}()
 
 	conn, err := net.Dial("tcp", "localhost:4000")
 	require.NoError(t, err)
@@ -241,11 +341,15 @@ func TestUseUDP(t *testing.T) {
 		_ = rcvr.Shutdown(context.Background())
 	}()
 	cancel, err := Run(rootDir, &config.Config{
-		Type:     "otlp_http",
-		Endpoint: "http://localhost:1343",
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1343",
+		},
 	})
 	require.NoError(t, err)
-	defer cancel()
+	defer func() {
+		assert.NoError(t, cancel(context.Background()))
+	}()
 	conn, err := net.Dial("udp", "127.0.0.1:4000")
 	require.NoError(t, err)
 	_, err = conn.Write([]byte("test"))
@@ -272,13 +376,611 @@ func TestRunScriptedInputsWithHEC(t *testing.T) {
 		_ = rcvr.Shutdown(context.Background())
 	}()
 	cancel, err := Run(filepath.Join("testdata", "script"), &config.Config{
-		Endpoint: "http://localhost:1341",
-		Token:    "foo",
+		Exporter: config.Exporter{
+			Type:     "splunk_hec",
+			Endpoint: "http://localhost:1341",
+			Token:    "foo",
+		},
 	})
 	require.NoError(t, err)
-	defer cancel()
+	defer func() {
+		assert.NoError(t, cancel(context.Background()))
+	}()
 
 	require.EventuallyWithT(t, func(tt *assert.CollectT) {
 		assert.GreaterOrEqual(tt, logsSink.LogRecordCount(), 1)
 	}, 2*time.Second, 10*time.Millisecond)
 }
+
+func TestRunScriptedInputsWithHECRawAndAck(t *testing.T) {
+	ackID := component.MustNewID("ack")
+	ack, err := ackextension.NewFactory().Create(context.Background(), extension.Settings{
+		ID:                ackID,
+		TelemetrySettings: componenttest.NewNopTelemetrySettings(),
+	}, ackextension.NewFactory().CreateDefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, ack.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		_ = ack.Shutdown(context.Background())
+	}()
+	logsSink := &consumertest.LogsSink{}
+	cfg := splunkhecreceiver.NewFactory().CreateDefaultConfig().(*splunkhecreceiver.Config)
+	cfg.NetAddr.Endpoint = "localhost:1342"
+	cfg.Ack.Extension = &ackID
+	rcvr, err := splunkhecreceiver.NewFactory().CreateLogs(context.Background(), receivertest.NewNopSettings(splunkhecreceiver.NewFactory().Type()), cfg, logsSink)
+	require.NoError(t, err)
+	err = rcvr.Start(context.Background(), host{extensions: map[component.ID]component.Component{ackID: ack}})
+	require.NoError(t, err)
+	defer func() {
+		_ = rcvr.Shutdown(context.Background())
+	}()
+	cancel, err := Run(filepath.Join("testdata", "script"), &config.Config{
+		Exporter: config.Exporter{
+			Type:     "splunk_hec",
+			Endpoint: "http://localhost:1342/services/collector",
+			Token:    "foo",
+			Raw:      true,
+			UseACK:   true,
+		},
+	})
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cancel(context.Background()))
+	}()
+
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		if assert.GreaterOrEqual(tt, logsSink.LogRecordCount(), 10) {
+			lr := logsSink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			assert.Equal(tt, "foo1", lr.Body().Str())
+			sourceType, _ := lr.Attributes().Get("com.splunk.sourcetype")
+			assert.Equal(tt, "_foo", sourceType.Str())
+		}
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestReload(t *testing.T) {
+	baseDir := t.TempDir()
+	app := filepath.Base(baseDir)
+	require.NoError(t, os.Mkdir(filepath.Join(baseDir, "default"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "default", "inputs.conf"), []byte(`[script://./bin/a.sh]
+interval = -1
+
+[script://./bin/b.sh]
+interval = -1
+`), 0o600))
+
+	c, err := Start(baseDir, &config.Config{
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1344",
+		},
+	})
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, c.Shutdown(context.Background()))
+	}()
+	require.Equal(t, 2, c.Inputs())
+	a := c.receivers[inputKey{app: app, stanza: "script://./bin/a.sh"}].receiver
+
+	require.NoError(t, os.Mkdir(filepath.Join(baseDir, "local"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "local", "inputs.conf"), []byte(`[script://./bin/a.sh]
+interval = -1
+
+[script://./bin/b.sh]
+interval = -1
+disabled = 1
+
+[script://./bin/c.sh]
+interval = -1
+`), 0o600))
+	require.NoError(t, c.Reload(&config.Config{
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1344",
+		},
+	}))
+	require.Equal(t, 2, c.Inputs())
+	require.Same(t, a, c.receivers[inputKey{app: app, stanza: "script://./bin/a.sh"}].receiver)
+	require.NotContains(t, c.receivers, inputKey{app: app, stanza: "script://./bin/b.sh"})
+	require.Contains(t, c.receivers, inputKey{app: app, stanza: "script://./bin/c.sh"})
+
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "local", "inputs.conf"), []byte(`[script://./bin/a.sh]
+interval = 3600
+
+[script://./bin/c.sh]
+interval = -1
+
+[badscheme://foo]
+`), 0o600))
+	require.ErrorContains(t, c.Reload(&config.Config{
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1344",
+		},
+	}), `unsupported scheme "badscheme"`)
+	require.Same(t, a, c.receivers[inputKey{app: app, stanza: "script://./bin/a.sh"}].receiver)
+
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "local", "inputs.conf"), []byte(`[script://./bin/a.sh]
+interval = -1
+sourcetype = foo
+
+[script://./bin/c.sh]
+interval = -1
+`), 0o600))
+	require.NoError(t, c.Reload(&config.Config{
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1344",
+		},
+	}))
+	require.Equal(t, 2, c.Inputs())
+	require.NotSame(t, a, c.receivers[inputKey{app: app, stanza: "script://./bin/a.sh"}].receiver)
+	previous := c.receivers[inputKey{app: app, stanza: "script://./bin/a.sh"}].input
+
+	// A changed input whose receiver fails to start keeps running with its previous configuration.
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "local", "inputs.conf"), []byte(`[script://./bin/a.sh]
+interval = never
+
+[script://./bin/c.sh]
+interval = -1
+`), 0o600))
+	require.ErrorContains(t, c.Reload(&config.Config{
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1344",
+		},
+	}), `failed to start receiver "script://./bin/a.sh"`)
+	require.Equal(t, 2, c.Inputs())
+	require.Equal(t, previous, c.receivers[inputKey{app: app, stanza: "script://./bin/a.sh"}].input)
+}
+
+func TestShutdownReportsDataLoss(t *testing.T) {
+	// nothing listens on the exporter endpoint.
+	c, err := Start(filepath.Join("testdata", "ta"), &config.Config{
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1345",
+		},
+	})
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	err = c.Shutdown(ctx)
+	require.ErrorIs(t, err, ErrDataLoss)
+}
+
+func TestRunApps(t *testing.T) {
+	logsSink := &consumertest.LogsSink{}
+	cfg := otlpreceiver.NewFactory().CreateDefaultConfig().(*otlpreceiver.Config)
+	cfg.HTTP.GetOrInsertDefault().ServerConfig.NetAddr.Endpoint = "localhost:1346"
+	rcvr, err := otlpreceiver.NewFactory().CreateLogs(context.Background(), receivertest.NewNopSettings(otlpreceiver.NewFactory().Type()), cfg, logsSink)
+	require.NoError(t, err)
+	err = rcvr.Start(context.Background(), componenttest.NewNopHost())
+	require.NoError(t, err)
+	defer func() {
+		_ = rcvr.Shutdown(context.Background())
+	}()
+	cancel, err := Run(filepath.Join("testdata", "apps"), &config.Config{
+		Exporter: config.Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:1346",
+		},
+	})
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cancel(context.Background()))
+	}()
+
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		bodies := map[string]string{}
+		for _, l := range logsSink.AllLogs() {
+			for _, rl := range l.ResourceLogs().All() {
+				for _, sl := range rl.ScopeLogs().All() {
+					for _, lr := range sl.LogRecords().All() {
+						sourceType, _ := lr.Attributes().Get("com.splunk.sourcetype")
+						bodies[sourceType.Str()] = lr.Body().Str()
+					}
+				}
+			}
+		}
+		assert.Contains(tt, bodies["one"], `<stanza name="script://./bin/app.sh" app="one">`)
+		assert.Contains(tt, bodies["two"], `<stanza name="script://./bin/app.sh" app="two">`)
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestFindTAs(t *testing.T) {
+	appsDir := filepath.Join("testdata", "apps")
+	tests := []struct {
+		name        string
+		baseDir     string
+		apps        []string
+		expected    []string
+		errExpected string
+	}{
+		{
+			name:     "ta",
+			baseDir:  filepath.Join("testdata", "ta"),
+			expected: []string{filepath.Join("testdata", "ta")},
+		},
+		{
+			name:     "apps folder",
+			baseDir:  appsDir,
+			expected: []string{filepath.Join(appsDir, "one"), filepath.Join(appsDir, "two")},
+		},
+		{
+			name:     "apps list",
+			baseDir:  appsDir,
+			apps:     []string{"two"},
+			expected: []string{filepath.Join(appsDir, "two")},
+		},
+		{
+			name:        "apps list with a folder that is not a TA",
+			baseDir:     appsDir,
+			apps:        []string{"notata"},
+			errExpected: "is not a TA",
+		},
+		{
+			name:        "no TA",
+			baseDir:     filepath.Join(appsDir, "notata"),
+			errExpected: "no TA found",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dirs, err := findTAs(test.baseDir, &config.Config{Apps: test.apps})
+			if test.errExpected != "" {
+				require.ErrorContains(t, err, test.errExpected)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, dirs)
+		})
+	}
+}
+
+func TestReadTAsWithLocalDir(t *testing.T) {
+	localDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "inputs.conf"), []byte("[script://./bin/app.sh]\ninterval = 60\n"), 0o600))
+
+	tas, err := readTAs(filepath.Join("testdata", "apps", "one"), &config.Config{}, localDir)
+	require.NoError(t, err)
+	require.Len(t, tas["one"].inputs, 1)
+	assert.Equal(t, "60", tas["one"].inputs[0].Configuration.Stanza.Params.Get("interval").Value)
+	assert.Equal(t, localDir, tas["one"].localDir)
+
+	_, err = readTAs(filepath.Join("testdata", "apps"), &config.Config{}, localDir)
+	require.ErrorContains(t, err, "a local folder can only be set when running a single TA")
+}
+
+func TestInspect(t *testing.T) {
+	report, err := Inspect(filepath.Join("testdata", "inspect"), &config.Config{})
+	require.NoError(t, err)
+	inputs := filepath.Join("testdata", "inspect", "default", "inputs.conf")
+	transforms := filepath.Join("testdata", "inspect", "default", "transforms.conf")
+	require.Len(t, report.Stanzas, 7)
+	assert.Equal(t, StanzaReport{App: "inspect", File: inputs, Stanza: "script://./bin/app.sh", Support: Partial, IgnoredKeys: []string{"start_by_shell"}}, report.Stanzas[0])
+	assert.Equal(t, StanzaReport{App: "inspect", File: inputs, Stanza: "monitor:///var/log/app.log", Support: Supported}, report.Stanzas[1])
+	assert.Equal(t, StanzaReport{App: "inspect", File: inputs, Stanza: "splunk_ta_app://input", Support: Unsupported, Reason: `unsupported scheme "splunk_ta_app"`}, report.Stanzas[2])
+	assert.Equal(t, Unsupported, report.Stanzas[3].Support)
+	assert.Equal(t, cookReason, report.Stanzas[3].Reason)
+
+	require.NoError(t, featuregate.GlobalRegistry().Set(featuregates.CookFeatureGate.ID(), true))
+	defer func() {
+		require.NoError(t, featuregate.GlobalRegistry().Set(featuregates.CookFeatureGate.ID(), false))
+	}()
+	report, err = Inspect(filepath.Join("testdata", "inspect"), &config.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TIME_FORMAT"}, report.Stanzas[3].IgnoredKeys)
+	assert.Equal(t, Partial, report.Stanzas[3].Support)
+	assert.Equal(t, Unsupported, report.Stanzas[4].Support)
+	assert.Equal(t, StanzaReport{App: "inspect", File: transforms, Stanza: "mask", Support: Supported}, report.Stanzas[5])
+	assert.Equal(t, Unsupported, report.Stanzas[6].Support)
+	assert.Equal(t, 2, report.Count(Supported))
+}
+
+func TestReadConf(t *testing.T) {
+	files, err := ReadConf(filepath.Join("testdata", "apps"), &config.Config{}, "inputs")
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "one", files[0].App)
+	assert.Equal(t, filepath.Join("testdata", "apps", "one", "default", "inputs.conf"), files[0].Path)
+	require.Len(t, files[0].Sections, 1)
+	assert.Equal(t, "script://./bin/app.sh", files[0].Sections[0].Name)
+	assert.Equal(t, "two", files[1].App)
+
+	files, err = ReadConf(filepath.Join("testdata", "apps"), &config.Config{}, "outputs.conf")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	files, err = ReadConf(filepath.Join("testdata", "transforms", "both"), &config.Config{}, "transforms")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, filepath.Join("testdata", "transforms", "both", "local", "transforms.conf"), files[0].Path)
+	require.NotNil(t, files[0].Overridden)
+	assert.Equal(t, filepath.Join("testdata", "transforms", "both", "default", "transforms.conf"), files[0].Overridden.Path)
+	require.Len(t, files[0].Overridden.Sections, 1)
+	assert.Equal(t, "example_default", files[0].Overridden.Sections[0].Name)
+
+	files, err = ReadConf(filepath.Join("testdata", "transforms", "local"), &config.Config{}, "transforms")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Nil(t, files[0].Overridden)
+}
+
+func TestDryRunOnce(t *testing.T) {
+	var out bytes.Buffer
+	c, err := Start(filepath.Join("testdata", "apps"), &config.Config{}, WithConsole(&out, ConsoleRaw, 0), WithOnce())
+	require.NoError(t, err)
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "scripts did not run once")
+	}
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.Contains(t, out.String(), `sourcetype=one | <?xml version="1.0" encoding="UTF-8"?>`)
+	assert.Contains(t, out.String(), `<stanza name="script://./bin/app.sh" app="two">`)
+}
+
+func TestDryRunMaxEvents(t *testing.T) {
+	var out bytes.Buffer
+	c, err := Start(filepath.Join("testdata", "periodic"), &config.Config{}, WithConsole(&out, ConsoleJSON, 1))
+	require.NoError(t, err)
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no event printed")
+	}
+	require.NoError(t, c.Shutdown(context.Background()))
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &event))
+	assert.Equal(t, "foo1\nfoo2\nfoo3\nfoo4\nfoo5\nfoo6\nfoo7\nfoo8\nfoo9\nfoo10\n", event["body"])
+	assert.Equal(t, "_foo", event["attributes"].(map[string]any)["com.splunk.sourcetype"])
+}
+
+func TestRunInputUnscheduled(t *testing.T) {
+	var out bytes.Buffer
+	c, err := Start(filepath.Join("testdata", "unscheduled"), &config.Config{}, WithConsole(&out, ConsoleRaw, 0),
+		WithInput("", "script://./bin/unscheduled.sh"), WithTimes(2))
+	require.NoError(t, err)
+	// The script runs its number of times even though its interval is -1.
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "script did not run twice")
+	}
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.Equal(t, 2, strings.Count(out.String(), "sourcetype=unscheduled | unscheduled"))
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, c.ScriptErrors(), &exitErr)
+	assert.Equal(t, 2, exitErr.ExitCode())
+
+	// Without a number of times, the script is not scheduled.
+	out.Reset()
+	c, err = Start(filepath.Join("testdata", "unscheduled"), &config.Config{}, WithConsole(&out, ConsoleRaw, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Inputs())
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.Empty(t, out.String())
+}
+
+func TestMetadataDefaults(t *testing.T) {
+	var out bytes.Buffer
+	c, err := Start(filepath.Join("testdata", "periodic"), &config.Config{
+		Metadata: config.Metadata{
+			Host:        "tarunner",
+			IndexRename: map[string]string{"": "main"},
+		},
+	}, WithConsole(&out, ConsoleJSON, 1))
+	require.NoError(t, err)
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no event printed")
+	}
+	require.NoError(t, c.Shutdown(context.Background()))
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &event))
+	attributes := event["attributes"].(map[string]any)
+	assert.Equal(t, "main", attributes["com.splunk.index"])
+	assert.Equal(t, "tarunner", attributes["com.splunk.host"])
+	assert.Equal(t, "_foo", attributes["com.splunk.sourcetype"])
+}
+
+func TestRunInput(t *testing.T) {
+	var out bytes.Buffer
+	c, err := Start(filepath.Join("testdata", "runinput"), &config.Config{}, WithConsole(&out, ConsoleRaw, 0),
+		WithInput("", "script://./bin/fail.sh"), WithTimes(2))
+	require.NoError(t, err)
+	// The input runs even though it is disabled, and is the only input run.
+	assert.Equal(t, 1, c.Inputs())
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "script did not run twice")
+	}
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.Equal(t, 2, strings.Count(out.String(), "sourcetype=fail | failing"))
+	assert.NotContains(t, out.String(), "other")
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, c.ScriptErrors(), &exitErr)
+	assert.Equal(t, 3, exitErr.ExitCode())
+
+	console := WithConsole(&out, ConsoleRaw, 0)
+	err = Validate(filepath.Join("testdata", "runinput"), &config.Config{}, console, WithInput("", "script://./bin/missing.sh"))
+	require.EqualError(t, err, `input "script://./bin/missing.sh" not found`)
+	err = Validate(filepath.Join("testdata", "apps"), &config.Config{}, console, WithInput("", "script://./bin/app.sh"))
+	require.ErrorContains(t, err, `input "script://./bin/app.sh" is defined by TAs`)
+	require.NoError(t, Validate(filepath.Join("testdata", "apps"), &config.Config{}, console, WithInput("two", "script://./bin/app.sh")))
+}
+
+func TestInputOverrides(t *testing.T) {
+	var out bytes.Buffer
+	cfg := &config.Config{
+		Inputs: map[string]map[string]any{
+			"script://./bin/*.sh":    {"sourcetype": "script"},
+			"script://./bin/fail.sh": {"disabled": false, "sourcetype": "overridden"},
+		},
+	}
+	c, err := Start(filepath.Join("testdata", "runinput"), cfg, WithConsole(&out, ConsoleRaw, 0), WithOnce(),
+		WithSelection(nil, []string{"script://./bin/other.*"}))
+	require.NoError(t, err)
+	// fail.sh is enabled by tarunner.yaml, and other.sh is excluded.
+	assert.Equal(t, 1, c.Inputs())
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "script did not run")
+	}
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.Contains(t, out.String(), "sourcetype=overridden | failing")
+	assert.NotContains(t, out.String(), "other")
+
+	console := WithConsole(&out, ConsoleRaw, 0)
+	err = Validate(filepath.Join("testdata", "runinput"), cfg, console, WithSelection([]string{"script://./bin/*", "tcp://*"}, nil))
+	require.EqualError(t, err, `no input matches "tcp://*"`)
+	require.NoError(t, Validate(filepath.Join("testdata", "runinput"), cfg, console, WithSelection([]string{"script://./bin/other.sh"}, nil)))
+}
+
+func TestMonitorOffsets(t *testing.T) {
+	logsSink := &consumertest.LogsSink{}
+	cfg := otlpreceiver.NewFactory().CreateDefaultConfig().(*otlpreceiver.Config)
+	http := cfg.HTTP.GetOrInsertDefault()
+	http.ServerConfig.NetAddr.Endpoint = "localhost:1349"
+	rcvr, err := otlpreceiver.NewFactory().CreateLogs(context.Background(), receivertest.NewNopSettings(otlpreceiver.NewFactory().Type()), cfg, logsSink)
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		_ = rcvr.Shutdown(context.Background())
+	}()
+
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(logFile, []byte("before\n"), 0o600))
+	baseDir := filepath.Join(dir, "ta")
+	require.NoError(t, os.MkdirAll(filepath.Join(baseDir, "default"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "default", "inputs.conf"), []byte("[monitor://"+logFile+"]\nsourcetype = app\n"), 0o600))
+	tarunnerCfg := &config.Config{
+		Exporter: config.Exporter{Type: "otlp_http", Endpoint: "http://localhost:1349"},
+		Storage:  &config.Storage{Directory: filepath.Join(dir, "storage")},
+	}
+	appendLine := func(line string) {
+		f, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0o600)
+		require.NoError(t, err)
+		_, err = f.WriteString(line + "\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+	bodies := func() []string {
+		var result []string
+		for _, ld := range logsSink.AllLogs() {
+			lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+			for i := 0; i < lrs.Len(); i++ {
+				// Monitor inputs read files without decoding them.
+				result = append(result, string(lrs.At(i).Body().Bytes().AsRaw()))
+			}
+		}
+		return result
+	}
+
+	c, err := Start(baseDir, tarunnerCfg)
+	require.NoError(t, err)
+	// Let the receiver find the file, which it reads from its end.
+	time.Sleep(500 * time.Millisecond)
+	appendLine("first")
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		assert.Equal(tt, []string{"first\n"}, bodies())
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, c.Shutdown(context.Background()))
+
+	// The line written while tarunner is stopped is read from the offset flushed on shutdown.
+	appendLine("second")
+	c, err = Start(baseDir, tarunnerCfg)
+	require.NoError(t, err)
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		assert.Equal(tt, []string{"first\n", "second\n"}, bodies())
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, c.Shutdown(context.Background()))
+}
+
+func TestDiskUsageLogged(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	storage := &config.Storage{Directory: filepath.Join(t.TempDir(), "storage")}
+	c, err := Start(filepath.Join("testdata", "disabled"), &config.Config{
+		Exporter: config.Exporter{
+			Type:         "otlp_http",
+			Endpoint:     "http://localhost:1350",
+			SendingQueue: map[string]any{"persistent": true},
+		},
+		Storage: storage,
+	}, WithLogger(zap.New(core)), withTelemetryInterval(50*time.Millisecond))
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, c.Shutdown(context.Background()))
+	}()
+	size, err := diskUsage(storage.Directory)
+	require.NoError(t, err)
+	require.Positive(t, size)
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		entries := logs.FilterMessage("Telemetry").FilterField(zap.String("metric", "tarunner_storage_disk_usage")).All()
+		if assert.NotEmpty(tt, entries) {
+			assert.Positive(tt, entries[len(entries)-1].ContextMap()["value"])
+		}
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestPersistentQueue(t *testing.T) {
+	storage := &config.Storage{Directory: filepath.Join(t.TempDir(), "storage")}
+	persistent := config.Exporter{
+		Type:         "otlp_http",
+		Endpoint:     "http://localhost:1347",
+		SendingQueue: map[string]any{"persistent": true},
+	}
+	// nothing listens on the exporter endpoint yet: log records stay in the queue.
+	c, err := Start(filepath.Join("testdata", "script"), &config.Config{Exporter: persistent, Storage: storage}, WithOnce())
+	require.NoError(t, err)
+	<-c.Done()
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		var rm metricdata.ResourceMetrics
+		assert.NoError(tt, c.settings.metricReader.Collect(context.Background(), &rm))
+		assert.Positive(tt, gaugeValue(rm, "tarunner_storage_disk_usage"))
+	}, 2*time.Second, 10*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	// the persistent queue keeps the log records the exporter could not send.
+	require.NoError(t, c.Shutdown(ctx))
+
+	logsSink := &consumertest.LogsSink{}
+	cfg := otlpreceiver.NewFactory().CreateDefaultConfig().(*otlpreceiver.Config)
+	cfg.HTTP.GetOrInsertDefault().ServerConfig.NetAddr.Endpoint = "localhost:1347"
+	rcvr, err := otlpreceiver.NewFactory().CreateLogs(context.Background(), receivertest.NewNopSettings(otlpreceiver.NewFactory().Type()), cfg, logsSink)
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		_ = rcvr.Shutdown(context.Background())
+	}()
+
+	// the queue is sent once the collector restarts, without running the script.
+	c, err = Start(filepath.Join("testdata", "disabled"), &config.Config{Exporter: persistent, Storage: storage})
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, c.Shutdown(context.Background()))
+	}()
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		assert.Positive(tt, logsSink.LogRecordCount())
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// gaugeValue returns the value of an int64 gauge, or 0 if it was not recorded.
+func gaugeValue(rm metricdata.ResourceMetrics, name string) int64 {
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if g, ok := m.Data.(metricdata.Gauge[int64]); ok && m.Name == name && len(g.DataPoints) > 0 {
+				return g.DataPoints[0].Value
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/splunk/tarunner/internal/conf"
+	"github.com/splunk/tarunner/internal/config"
+)
+
+// ConfFile is a configuration file read by tarunner for a TA.
+type ConfFile struct {
+	App      string
+	Path     string
+	Sections []conf.Section
+	// Overridden is the file of the default folder that tarunner ignores because the local folder has the file.
+	Overridden *ConfFile
+}
+
+// ReadConf returns, for each TA located in baseDir, the configuration file named name (such as inputs)
+// that tarunner uses: the file of the local folder if it exists, or else the file of the default folder.
+// TAs without such file are skipped. Files are sorted by app name.
+func ReadConf(baseDir string, cfg *config.Config, name string, opts ...Option) ([]ConfFile, error) {
+	s, err := newSettings(opts)
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := findTAs(baseDir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if s.localDir != "" && len(dirs) > 1 {
+		return nil, fmt.Errorf("a local folder can only be set when running a single TA, found %d TAs", len(dirs))
+	}
+	if !strings.HasSuffix(name, ".conf") {
+		name += ".conf"
+	}
+	var files []ConfFile
+	for _, dir := range dirs {
+		app, err := appName(dir)
+		if err != nil {
+			return nil, err
+		}
+		path, err := confPath(dir, taLocalDir(dir, s.localDir), name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		file, err := readConfFile(app, path)
+		if err != nil {
+			return nil, err
+		}
+		if defaultPath := filepath.Join(dir, "default", name); defaultPath != path {
+			overridden, err := readConfFile(app, defaultPath)
+			switch {
+			case err == nil:
+				file.Overridden = overridden
+			case !errors.Is(err, os.ErrNotExist):
+				return nil, err
+			}
+		}
+		files = append(files, *file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].App < files[j].App
+	})
+	return files, nil
+}
+
+func readConfFile(app string, path string) (*ConfFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := conf.ReadSections(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &ConfFile{App: app, Path: path, Sections: sections}, nil
+}
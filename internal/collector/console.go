@@ -0,0 +1,132 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Formats of the console exporter.
+const (
+	ConsoleJSON = "json"
+	ConsoleRaw  = "raw"
+)
+
+type consoleSettings struct {
+	out       io.Writer
+	format    string
+	maxEvents int
+}
+
+// consoleExporter prints log records instead of exporting them, for dry runs.
+type consoleExporter struct {
+	settings consoleSettings
+	// complete is called once maxEvents log records were printed.
+	complete func()
+	mu       sync.Mutex
+	printed  int
+}
+
+func newConsoleExporter(s consoleSettings, complete func()) (*consoleExporter, error) {
+	if s.format != ConsoleJSON && s.format != ConsoleRaw {
+		return nil, fmt.Errorf("unknown console format %q, expected %q or %q", s.format, ConsoleJSON, ConsoleRaw)
+	}
+	return &consoleExporter{settings: s, complete: complete}, nil
+}
+
+func (e *consoleExporter) Start(context.Context, component.Host) error {
+	return nil
+}
+
+func (e *consoleExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (e *consoleExporter) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{}
+}
+
+func (e *consoleExporter) ConsumeLogs(_ context.Context, ld plog.Logs) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, rl := range ld.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, lr := range sl.LogRecords().All() {
+				if e.settings.maxEvents > 0 && e.printed >= e.settings.maxEvents {
+					return nil
+				}
+				var err error
+				if e.settings.format == ConsoleJSON {
+					err = e.printJSON(lr)
+				} else {
+					err = e.printRaw(lr)
+				}
+				if err != nil {
+					return err
+				}
+				e.printed++
+				if e.printed == e.settings.maxEvents {
+					e.complete()
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// printJSON prints a log record as a JSON object on a single line.
+func (e *consoleExporter) printJSON(lr plog.LogRecord) error {
+	b, err := json.Marshal(struct {
+		Time       string         `json:"time"`
+		Attributes map[string]any `json:"attributes"`
+		Body       any            `json:"body"`
+	}{
+		Time:       recordTime(lr).Format(time.RFC3339Nano),
+		Attributes: lr.Attributes().AsRaw(),
+		Body:       lr.Body().AsRaw(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.settings.out, "%s\n", b)
+	return err
+}
+
+// printRaw prints a log record like a Splunk event: its time and metadata, followed by its raw text.
+func (e *consoleExporter) printRaw(lr plog.LogRecord) error {
+	var sb strings.Builder
+	sb.WriteString(recordTime(lr).Format(time.RFC3339Nano))
+	for _, field := range []string{"index", "host", "source", "sourcetype"} {
+		if v, ok := lr.Attributes().Get("com.splunk." + field); ok {
+			value := v.AsString()
+			if strings.ContainsAny(value, " \t\"") {
+				value = strconv.Quote(value)
+			}
+			sb.WriteString(" " + field + "=" + value)
+		}
+	}
+	sb.WriteString(" | ")
+	sb.WriteString(strings.TrimRight(lr.Body().AsString(), "\n"))
+	_, err := fmt.Fprintln(e.settings.out, sb.String())
+	return err
+}
+
+// recordTime returns the time of the event, or the time it was observed if the event has no time.
+func recordTime(lr plog.LogRecord) time.Time {
+	if lr.Timestamp() != 0 {
+		return lr.Timestamp().AsTime()
+	}
+	return lr.ObservedTimestamp().AsTime()
+}
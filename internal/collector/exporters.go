@@ -0,0 +1,48 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/exporter"
+
+	"github.com/splunk/tarunner/internal/config"
+)
+
+// newExporter creates the exporter described by cfg.
+// A persistent sending queue is kept in storage.
+func newExporter(set component.TelemetrySettings, name string, cfg config.Exporter, storage queueStorage) (exporter.Logs, error) {
+	switch cfg.Type {
+	case "otlp_http":
+		return newOtlpHttpExporter(set, name, cfg, storage)
+	case "otlp_grpc":
+		return newOtlpGrpcExporter(set, name, cfg, storage)
+	case "splunk_s2s":
+		return newS2SExporter(set, name, cfg, storage)
+	case "splunk_hec":
+		return newHECExporter(set, name, cfg, storage)
+	case "syslog":
+		return newSyslogExporter(set, name, cfg, storage)
+	case "file":
+		return newFileExporter(set, name, cfg, storage)
+	default:
+		return nil, fmt.Errorf("unknown exporter type %q", cfg.Type)
+	}
+}
+
+// setClientConfig applies the endpoint, TLS, proxy, headers and timeout settings of cfg to the HTTP client of an exporter.
+func setClientConfig(client *confighttp.ClientConfig, cfg config.Exporter) {
+	client.Endpoint = cfg.Endpoint
+	client.TLS = cfg.TLS
+	client.ProxyURL = cfg.ProxyURL
+	for name, value := range cfg.Headers.Iter {
+		client.Headers.Set(name, value)
+	}
+	if cfg.Timeout > 0 {
+		client.Timeout = cfg.Timeout
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/splunk/tarunner/internal/config"
+)
+
+func TestOtlpHttpExporterWithTLS(t *testing.T) {
+	requests := make(chan *http.Request, 10)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	var headers configopaque.MapList
+	headers.Set("X-Tenant", "security")
+	cfg := config.Exporter{
+		Type:        "otlp_http",
+		Endpoint:    server.URL,
+		TLS:         configtls.ClientConfig{Config: configtls.Config{CAFile: caFile}},
+		Headers:     headers,
+		Compression: "none",
+		Timeout:     5 * time.Second,
+	}
+	require.NoError(t, cfg.Validate())
+	e, err := newExporter(componenttest.NewNopTelemetrySettings(), "secure", cfg, queueStorage{})
+	require.NoError(t, err)
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, e.Shutdown(context.Background()))
+	}()
+
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("foo")
+	require.NoError(t, e.ConsumeLogs(context.Background(), ld))
+	select {
+	case r := <-requests:
+		assert.Equal(t, "/v1/logs", r.URL.Path)
+		assert.Equal(t, "security", r.Header.Get("X-Tenant"))
+		assert.Empty(t, r.Header.Get("Content-Encoding"))
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no request received")
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter"
+
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/exporter/fileexporter"
+)
+
+func newFileExporter(set component.TelemetrySettings, name string, eCfg config.Exporter, storage queueStorage) (exporter.Logs, error) {
+	f := fileexporter.NewFactory()
+	cfg := f.CreateDefaultConfig().(*fileexporter.Config)
+	cfg.Directory = eCfg.Directory
+	if eCfg.Format != "" {
+		cfg.Format = eCfg.Format
+	}
+	// Files are compressed with gzip, unless compression is disabled.
+	cfg.Compress = eCfg.Compression != "none"
+	if eCfg.MaxFileSizeMiB > 0 {
+		cfg.MaxFileSizeMiB = eCfg.MaxFileSizeMiB
+	}
+	if eCfg.MaxFileAge > 0 {
+		cfg.MaxFileAge = eCfg.MaxFileAge
+	}
+	if eCfg.MaxSpoolSizeMiB > 0 {
+		cfg.MaxSpoolSizeMiB = eCfg.MaxSpoolSizeMiB
+	}
+	if err := eCfg.ApplyQueueAndRetry(&cfg.QueueConfig, &cfg.RetryConfig, storage.id, storage.maxBytes); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return f.CreateLogs(context.Background(), exporter.Settings{
+		ID:                component.NewIDWithName(f.Type(), name),
+		TelemetrySettings: set,
+	}, cfg)
+}
@@ -6,33 +6,65 @@ package collector
 import (
 	"context"
 
-	"go.opentelemetry.io/collector/config/configopaque"
-
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/splunkhecexporter"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/exporter"
-	metricnoop "go.opentelemetry.io/otel/metric/noop"
-	"go.opentelemetry.io/otel/trace/noop"
-	"go.uber.org/zap"
+
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/exporter/hecexporter"
 )
 
-func newHECExporter(logger *zap.Logger, endpoint, token string) (exporter.Logs, error) {
+// newHECExporter creates a splunk_hec exporter. The exporter of tarunner replaces the contrib exporter
+// to send to the raw endpoint, or to wait for indexer acknowledgement.
+func newHECExporter(set component.TelemetrySettings, name string, eCfg config.Exporter, storage queueStorage) (exporter.Logs, error) {
+	if eCfg.Raw || eCfg.UseACK {
+		return newHECChannelExporter(set, name, eCfg, storage)
+	}
 	f := splunkhecexporter.NewFactory()
 	cfg := f.CreateDefaultConfig().(*splunkhecexporter.Config)
-	cfg.Endpoint = endpoint
-	cfg.Token = configopaque.String(token)
+	setClientConfig(&cfg.ClientConfig, eCfg)
+	cfg.Token = eCfg.Token
+	// The HEC exporter compresses requests with gzip, unless compression is disabled.
+	cfg.DisableCompression = eCfg.Compression == "none"
+	if err := eCfg.ApplyQueueAndRetry(&cfg.QueueSettings, &cfg.BackOffConfig, storage.id, storage.maxBytes); err != nil {
+		return nil, err
+	}
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
 	e, err := f.CreateLogs(context.Background(), exporter.Settings{
-		ID: component.MustNewID(f.Type().String()),
-		TelemetrySettings: component.TelemetrySettings{
-			Logger:         logger,
-			TracerProvider: noop.NewTracerProvider(),
-			MeterProvider:  metricnoop.NewMeterProvider(),
-		},
+		ID:                component.NewIDWithName(f.Type(), name),
+		TelemetrySettings: set,
 	}, cfg)
 
 	return e, err
 }
+
+// newHECChannelExporter creates an exporter sending events to HEC on a channel, to the raw endpoint if set,
+// and waiting for indexers to acknowledge them if set.
+func newHECChannelExporter(set component.TelemetrySettings, name string, eCfg config.Exporter, storage queueStorage) (exporter.Logs, error) {
+	f := hecexporter.NewFactory()
+	cfg := f.CreateDefaultConfig().(*hecexporter.Config)
+	setClientConfig(&cfg.ClientConfig, eCfg)
+	// The timeout bounds the time to send a batch and to wait for its acknowledgement.
+	cfg.ClientConfig.Timeout = 0
+	if eCfg.Timeout > 0 {
+		cfg.TimeoutConfig.Timeout = eCfg.Timeout
+	}
+	cfg.Token = eCfg.Token
+	cfg.Raw = eCfg.Raw
+	cfg.UseACK = eCfg.UseACK
+	cfg.DisableCompression = eCfg.Compression == "none"
+	if err := eCfg.ApplyQueueAndRetry(&cfg.QueueConfig, &cfg.RetryConfig, storage.id, storage.maxBytes); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return f.CreateLogs(context.Background(), exporter.Settings{
+		ID:                component.NewIDWithName(f.Type(), name),
+		TelemetrySettings: set,
+	}, cfg)
+}
@@ -3,10 +3,32 @@
 
 package collector
 
-import "go.opentelemetry.io/collector/component"
+import (
+	"fmt"
 
-type host struct{}
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/splunk/tarunner/internal/config"
+)
+
+// host gives components access to the extensions run by the collector.
+type host struct {
+	extensions map[component.ID]component.Component
+}
+
+// newHost creates the extensions used by the exporters and receivers of cfg: the file storage extension
+// if an exporter has a persistent queue, or if storage.directory is set to keep the offsets of monitor inputs.
+func newHost(cfg *config.Config, s *settings) (host, error) {
+	if s.console != nil || (newQueueStorage(cfg).id == nil && offsetStorage(cfg, s) == nil) {
+		return host{}, nil
+	}
+	ext, err := newStorage(s.telemetrySettings(), *cfg.Storage)
+	if err != nil {
+		return host{}, fmt.Errorf("storage: %w", err)
+	}
+	return host{extensions: map[component.ID]component.Component{storageID: ext}}, nil
+}
 
 func (h host) GetExtensions() map[component.ID]component.Component {
-	return nil
+	return h.extensions
 }
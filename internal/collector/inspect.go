@@ -0,0 +1,256 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/splunk/tarunner/internal/conf"
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/featuregates"
+)
+
+// Support levels of a stanza.
+const (
+	Supported   = "supported"
+	Partial     = "partial"
+	Unsupported = "unsupported"
+)
+
+// StanzaReport tells how tarunner handles a stanza of a TA configuration file.
+type StanzaReport struct {
+	App    string `json:"app"`
+	File   string `json:"file"`
+	Stanza string `json:"stanza"`
+	// Support is one of Supported, Partial or Unsupported.
+	Support string `json:"support"`
+	// IgnoredKeys lists the keys of the stanza that tarunner does not use.
+	IgnoredKeys []string `json:"ignored_keys,omitempty"`
+	Reason      string   `json:"reason,omitempty"`
+	Disabled    bool     `json:"disabled,omitempty"`
+}
+
+// Report lists the stanzas of the inputs.conf, props.conf, transforms.conf and outputs.conf files of TAs.
+type Report struct {
+	Stanzas []StanzaReport `json:"stanzas"`
+}
+
+// Count returns the number of stanzas with the given support level.
+func (r *Report) Count(support string) int {
+	count := 0
+	for _, s := range r.Stanzas {
+		if s.Support == support {
+			count++
+		}
+	}
+	return count
+}
+
+// inputKeys lists the keys of inputs.conf used by tarunner, per scheme.
+var inputKeys = map[string][]string{
+	"script":      {"interval"},
+	"monitor":     {"whitelist", "blacklist"},
+	"WinEventLog": nil,
+	"tcp":         nil,
+	"udp":         nil,
+}
+
+// commonInputKeys lists the keys of inputs.conf used by all inputs.
+var commonInputKeys = []string{"disabled", "host", "index", "source", "sourcetype", "_TCP_ROUTING", "_SYSLOG_ROUTING"}
+
+// sslOutputKeys lists the TLS keys of outputs.conf used by tarunner.
+var sslOutputKeys = []string{"useSSL", "clientCert", "sslPassword", "sslRootCAPath", "sslCommonNameToCheck", "sslVerifyServerCert"}
+
+// tcpoutKeys lists the keys of tcpout stanzas of outputs.conf used by tarunner.
+var tcpoutKeys = []string{"disabled", "server", "sendCookedData", "useACK", "autoLBFrequency"}
+
+// syslogKeys lists the keys of syslog stanzas of outputs.conf used by tarunner.
+var syslogKeys = []string{"disabled", "server", "type", "priority", "timestampformat", "syslogSourceType", "maxEventSize"}
+
+// cookReason explains why props and transforms are ignored when the cook feature gate is disabled.
+const cookReason = "props.conf and transforms.conf are only applied in HF mode, enable the cook feature gate"
+
+// Inspect reads the TAs located in baseDir and reports, for each stanza of their inputs.conf,
+// props.conf, transforms.conf and outputs.conf files, whether tarunner supports it.
+func Inspect(baseDir string, cfg *config.Config, opts ...Option) (*Report, error) {
+	s, err := newSettings(opts)
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := findTAs(baseDir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if s.localDir != "" && len(dirs) > 1 {
+		return nil, fmt.Errorf("a local folder can only be set when running a single TA, found %d TAs", len(dirs))
+	}
+	report := &Report{}
+	for _, dir := range dirs {
+		name, err := appName(dir)
+		if err != nil {
+			return nil, err
+		}
+		localDir := taLocalDir(dir, s.localDir)
+		for _, file := range []struct {
+			name    string
+			inspect func(conf.Stanza) StanzaReport
+		}{
+			{"inputs.conf", inspectInput},
+			{"props.conf", inspectProp},
+			{"transforms.conf", inspectTransform},
+			{"outputs.conf", inspectOutput},
+		} {
+			path, err := confPath(dir, localDir, file.name)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			stanzas, err := conf.ReadStanzas(b)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			for _, stanza := range stanzas {
+				r := file.inspect(stanza)
+				r.App = name
+				r.File = path
+				r.Stanza = stanza.Name
+				report.Stanzas = append(report.Stanzas, r)
+			}
+		}
+	}
+	return report, nil
+}
+
+func inspectInput(stanza conf.Stanza) StanzaReport {
+	r := StanzaReport{Disabled: isDisabled(conf.Input{Configuration: conf.Configuration{Stanza: stanza}})}
+	parsed, err := url.Parse(stanza.Name)
+	if err != nil {
+		r.Support = Unsupported
+		r.Reason = err.Error()
+		if scheme, _, found := strings.Cut(stanza.Name, "://"); found {
+			if _, ok := inputKeys[scheme]; !ok {
+				// Modular inputs often have names that are not valid URL schemes.
+				r.Reason = fmt.Sprintf("unsupported scheme %q", scheme)
+			}
+		}
+		return r
+	}
+	scheme := parsed.Scheme
+	if scheme == "" {
+		scheme = "script"
+	}
+	keys, ok := inputKeys[scheme]
+	if !ok {
+		r.Support = Unsupported
+		r.Reason = fmt.Sprintf("unsupported scheme %q", parsed.Scheme)
+		return r
+	}
+	if scheme == "WinEventLog" && runtime.GOOS != "windows" {
+		r.Support = Unsupported
+		r.Reason = "WinEventLog inputs are only supported on Windows"
+		return r
+	}
+	if scheme == "script" {
+		if interval := stanza.Params.Get("interval"); interval != nil {
+			if _, err := strconv.Atoi(interval.Value); err != nil {
+				r.Support = Unsupported
+				r.Reason = fmt.Sprintf("interval %q is not a number of seconds, cron schedules are not supported", interval.Value)
+				return r
+			}
+		}
+	}
+	return withIgnoredKeys(r, stanza, func(key string) bool {
+		return slices.Contains(commonInputKeys, key) || slices.Contains(keys, key)
+	})
+}
+
+func inspectProp(stanza conf.Stanza) StanzaReport {
+	if !featuregates.CookFeatureGate.IsEnabled() {
+		return allIgnored(stanza, cookReason)
+	}
+	r := withIgnoredKeys(StanzaReport{}, stanza, func(key string) bool {
+		return strings.HasPrefix(key, "TRANSFORMS-") || strings.HasPrefix(key, "FIELDALIAS-")
+	})
+	if len(r.IgnoredKeys) > 0 && len(r.IgnoredKeys) == len(stanza.Params) {
+		r.Support = Unsupported
+		r.Reason = "only TRANSFORMS- and FIELDALIAS- keys are supported"
+	}
+	return r
+}
+
+func inspectTransform(stanza conf.Stanza) StanzaReport {
+	if !featuregates.CookFeatureGate.IsEnabled() {
+		return allIgnored(stanza, cookReason)
+	}
+	if regex := stanza.Params.Get("REGEX"); regex == nil || regex.Value == "" {
+		return allIgnored(stanza, "only transforms with a REGEX are supported")
+	}
+	return withIgnoredKeys(StanzaReport{}, stanza, func(key string) bool {
+		return key == "REGEX" || key == "FORMAT"
+	})
+}
+
+func inspectOutput(stanza conf.Stanza) StanzaReport {
+	switch {
+	case stanza.Name == "tcpout":
+		return withIgnoredKeys(StanzaReport{}, stanza, func(key string) bool {
+			return key == "defaultGroup" || slices.Contains(tcpoutKeys, key) || slices.Contains(sslOutputKeys, key)
+		})
+	case strings.HasPrefix(stanza.Name, "tcpout:"):
+		return withIgnoredKeys(StanzaReport{}, stanza, func(key string) bool {
+			return slices.Contains(tcpoutKeys, key) || slices.Contains(sslOutputKeys, key)
+		})
+	case stanza.Name == "syslog":
+		return withIgnoredKeys(StanzaReport{}, stanza, func(key string) bool {
+			return key == "defaultGroup" || slices.Contains(syslogKeys, key)
+		})
+	case strings.HasPrefix(stanza.Name, "syslog:"):
+		return withIgnoredKeys(StanzaReport{}, stanza, func(key string) bool {
+			return slices.Contains(syslogKeys, key)
+		})
+	case stanza.Name == "httpout":
+		return withIgnoredKeys(StanzaReport{}, stanza, func(key string) bool {
+			return key == "disabled" || key == "uri" || key == "httpEventCollectorToken" || slices.Contains(sslOutputKeys, key)
+		})
+	default:
+		return allIgnored(stanza, "only the tcpout, syslog and httpout stanzas are supported")
+	}
+}
+
+// withIgnoredKeys lists the keys of stanza that are not used in r, and sets its support level accordingly.
+func withIgnoredKeys(r StanzaReport, stanza conf.Stanza, used func(key string) bool) StanzaReport {
+	for _, p := range stanza.Params {
+		if !used(p.Name) {
+			r.IgnoredKeys = append(r.IgnoredKeys, p.Name)
+		}
+	}
+	sort.Strings(r.IgnoredKeys)
+	r.Support = Supported
+	if len(r.IgnoredKeys) > 0 {
+		r.Support = Partial
+	}
+	return r
+}
+
+// allIgnored reports a stanza of which no key is used.
+func allIgnored(stanza conf.Stanza, reason string) StanzaReport {
+	r := withIgnoredKeys(StanzaReport{}, stanza, func(string) bool { return false })
+	r.Support = Unsupported
+	r.Reason = reason
+	return r
+}
@@ -9,26 +9,27 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/exporter/otlphttpexporter"
-	metricnoop "go.opentelemetry.io/otel/metric/noop"
-	"go.opentelemetry.io/otel/trace/noop"
-	"go.uber.org/zap"
+
+	"github.com/splunk/tarunner/internal/config"
 )
 
-func newOtlpHttpExporter(logger *zap.Logger, endpoint string) (exporter.Logs, error) {
+func newOtlpHttpExporter(set component.TelemetrySettings, name string, eCfg config.Exporter, storage queueStorage) (exporter.Logs, error) {
 	f := otlphttpexporter.NewFactory()
 	cfg := f.CreateDefaultConfig().(*otlphttpexporter.Config)
-	cfg.ClientConfig.Endpoint = endpoint
+	setClientConfig(&cfg.ClientConfig, eCfg)
+	if eCfg.Compression != "" {
+		cfg.ClientConfig.Compression = eCfg.Compression
+	}
+	if err := eCfg.ApplyQueueAndRetry(&cfg.QueueConfig, &cfg.RetryConfig, storage.id, storage.maxBytes); err != nil {
+		return nil, err
+	}
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
 	e, err := f.CreateLogs(context.Background(), exporter.Settings{
-		ID: component.MustNewID(f.Type().String()),
-		TelemetrySettings: component.TelemetrySettings{
-			Logger:         logger,
-			TracerProvider: noop.NewTracerProvider(),
-			MeterProvider:  metricnoop.NewMeterProvider(),
-		},
+		ID:                component.NewIDWithName(f.Type(), name),
+		TelemetrySettings: set,
 	}, cfg)
 
 	return e, err
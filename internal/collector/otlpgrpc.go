@@ -0,0 +1,48 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+
+	"github.com/splunk/tarunner/internal/config"
+)
+
+func newOtlpGrpcExporter(set component.TelemetrySettings, name string, eCfg config.Exporter, storage queueStorage) (exporter.Logs, error) {
+	f := otlpexporter.NewFactory()
+	cfg := f.CreateDefaultConfig().(*otlpexporter.Config)
+	cfg.ClientConfig.Endpoint = eCfg.Endpoint
+	cfg.ClientConfig.TLS = eCfg.TLS
+	// Like the OTLP HTTP exporter, connect without TLS to an http:// endpoint.
+	if strings.HasPrefix(eCfg.Endpoint, "http://") {
+		cfg.ClientConfig.TLS.Insecure = true
+	}
+	for name, value := range eCfg.Headers.Iter {
+		cfg.ClientConfig.Headers.Set(name, value)
+	}
+	if eCfg.Compression != "" {
+		cfg.ClientConfig.Compression = eCfg.Compression
+	}
+	if eCfg.Timeout > 0 {
+		cfg.TimeoutConfig.Timeout = eCfg.Timeout
+	}
+	if err := eCfg.ApplyQueueAndRetry(&cfg.QueueConfig, &cfg.RetryConfig, storage.id, storage.maxBytes); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	e, err := f.CreateLogs(context.Background(), exporter.Settings{
+		ID:                component.NewIDWithName(f.Type(), name),
+		TelemetrySettings: set,
+	}, cfg)
+
+	return e, err
+}
@@ -0,0 +1,315 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/consumer"
+	"go.uber.org/zap"
+
+	"github.com/splunk/tarunner/internal/conf"
+	"github.com/splunk/tarunner/internal/config"
+)
+
+// Prefixes of the names of the exporters declared by outputs.conf, followed by the name of their group.
+const (
+	tcpoutPrefix = "tcpout:"
+	syslogPrefix = "syslog:"
+)
+
+// httpoutExporter is the name of the exporter declared by the [httpout] stanza of outputs.conf.
+const httpoutExporter = "httpout"
+
+// readOutputs reads the outputs.conf files of the TAs and of the system folder, if set.
+// As in Splunk, the system folder takes precedence over the TAs, and TAs over the TAs following them in name order.
+func readOutputs(tas map[string]*ta, systemDir string) (*conf.Outputs, error) {
+	names := make([]string, 0, len(tas))
+	for name := range tas {
+		names = append(names, name)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	var paths []string
+	for _, name := range names {
+		path, err := confPath(tas[name].dir, tas[name].localDir, "outputs.conf")
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	if systemDir != "" {
+		path := filepath.Join(systemDir, "outputs.conf")
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	payloads := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, b)
+	}
+	outputs, err := conf.ReadOutputs(payloads...)
+	if err != nil {
+		return nil, fmt.Errorf("outputs.conf: %w", err)
+	}
+	return outputs, nil
+}
+
+// withOutputs returns cfg declaring the exporters of outputs.conf along with its named exporters:
+// a splunk_s2s exporter named tcpout:<group> per tcpout group, a syslog exporter named syslog:<group> per syslog group,
+// and a splunk_hec exporter named httpout. Exporters of cfg take precedence over exporters of the same name.
+// Unless cfg sets default_route, events no route matches are sent to the named exporters of cfg,
+// to the default tcpout and syslog groups, and to httpout.
+func withOutputs(cfg *config.Config, outputs *conf.Outputs, logger *zap.Logger) (*config.Config, error) {
+	if len(outputs.TCPOut) == 0 && len(outputs.Syslog) == 0 && outputs.HTTPOut == nil {
+		return cfg, nil
+	}
+	if cfg.ExporterSet() {
+		return nil, errors.New("the type, endpoint and token of tarunner.yaml cannot be used with outputs.conf, declare them under a named exporter")
+	}
+	exporters := map[string]config.Exporter{}
+	var defaults []string
+	sec := &secrets{path: cfg.SplunkSecret}
+	for _, g := range outputs.TCPOut {
+		tls, err := tlsConfig(g.SSL, sec)
+		if err != nil {
+			return nil, fmt.Errorf("outputs.conf [tcpout:%s]: %w", g.Name, err)
+		}
+		name := tcpoutPrefix + g.Name
+		warnInsecure(logger, name, tls)
+		exporters[name] = config.Exporter{
+			Type:            "splunk_s2s",
+			Servers:         g.Servers,
+			TLS:             tls,
+			UseTLS:          g.SSL.Enabled(),
+			SendCookedData:  g.SendCookedData,
+			UseACK:          g.UseACK,
+			AutoLBFrequency: g.AutoLBFrequency,
+		}
+		if len(outputs.DefaultGroups) == 0 || slices.Contains(outputs.DefaultGroups, g.Name) {
+			defaults = append(defaults, name)
+		}
+	}
+	for _, g := range outputs.Syslog {
+		name := syslogPrefix + g.Name
+		exporters[name] = config.Exporter{
+			Type:     "syslog",
+			Endpoint: g.Server,
+			Protocol: g.Type,
+			// Splunk sends RFC 3164 messages.
+			Format:           "rfc3164",
+			Priority:         g.Priority,
+			TimestampFormat:  g.TimestampFormat,
+			SyslogSourceType: strings.TrimPrefix(g.SyslogSourceType, "sourcetype::"),
+			MaxEventSize:     g.MaxEventSize,
+		}
+		// Unlike tcpout groups, syslog groups only receive all events if they are default groups.
+		if slices.Contains(outputs.SyslogDefaultGroups, g.Name) {
+			defaults = append(defaults, name)
+		}
+	}
+	if h := outputs.HTTPOut; h != nil {
+		u, err := url.Parse(h.URI)
+		if err != nil {
+			return nil, fmt.Errorf("outputs.conf [httpout]: uri: %w", err)
+		}
+		if u.Path == "" || u.Path == "/" {
+			u.Path = "/services/collector"
+		}
+		var tls configtls.ClientConfig
+		if u.Scheme == "https" {
+			// httpout uses TLS according to the scheme of its URI.
+			ssl := h.SSL
+			ssl.UseSSL = "true"
+			if tls, err = tlsConfig(ssl, sec); err != nil {
+				return nil, fmt.Errorf("outputs.conf [httpout]: %w", err)
+			}
+		}
+		warnInsecure(logger, httpoutExporter, tls)
+		exporters[httpoutExporter] = config.Exporter{
+			Type:     "splunk_hec",
+			Endpoint: u.String(),
+			Token:    configopaque.String(h.Token),
+			TLS:      tls,
+		}
+		defaults = append(defaults, httpoutExporter)
+	}
+
+	merged := *cfg
+	merged.Exporters = exporters
+	maps.Copy(merged.Exporters, cfg.Exporters)
+	if len(cfg.DefaultRoute) == 0 {
+		merged.DefaultRoute = defaults
+		for name := range cfg.Exporters {
+			if !slices.Contains(merged.DefaultRoute, name) {
+				merged.DefaultRoute = append(merged.DefaultRoute, name)
+			}
+		}
+		sort.Strings(merged.DefaultRoute)
+	}
+	return &merged, nil
+}
+
+// tlsConfig returns the TLS settings of an output. As in Splunk, the certificate of servers is only verified
+// if sslVerifyServerCert is set, and the client certificate holds the certificate and its private key,
+// encrypted with sslPassword if set.
+func tlsConfig(s conf.SSL, sec *secrets) (configtls.ClientConfig, error) {
+	var tls configtls.ClientConfig
+	if !s.Enabled() {
+		return tls, nil
+	}
+	tls.CAFile = s.SSLRootCAPath
+	if s.SSLPassword != "" && s.ClientCert != "" {
+		password, err := sec.decrypt(s.SSLPassword)
+		if err != nil {
+			return tls, fmt.Errorf("sslPassword: %w", err)
+		}
+		certPEM, keyPEM, err := decryptClientCert(s.ClientCert, password)
+		if err != nil {
+			return tls, fmt.Errorf("clientCert: %w", err)
+		}
+		tls.CertPem = configopaque.String(certPEM)
+		tls.KeyPem = configopaque.String(keyPEM)
+	} else {
+		tls.CertFile = s.ClientCert
+		tls.KeyFile = s.ClientCert
+	}
+	tls.InsecureSkipVerify = !s.SSLVerifyServerCert
+	if name, _, _ := strings.Cut(s.SSLCommonNameToCheck, ","); name != "" {
+		tls.ServerName = strings.TrimSpace(name)
+	}
+	return tls, nil
+}
+
+// warnInsecure warns that the exporter named name does not verify the certificate of servers,
+// as outputs.conf groups using TLS without sslVerifyServerCert.
+func warnInsecure(logger *zap.Logger, name string, tls configtls.ClientConfig) {
+	if tls.InsecureSkipVerify {
+		logger.Warn("The certificate of servers is not verified, set sslVerifyServerCert = true in outputs.conf",
+			zap.String("exporter", name))
+	}
+}
+
+// decryptClientCert reads a client certificate file holding the certificate and its private key,
+// and returns the certificate and the private key decrypted with password, in PEM.
+// Only keys encrypted in the legacy PEM format, with a Proc-Type header, are supported.
+func decryptClientCert(path string, password string) ([]byte, []byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var certPEM, keyPEM []byte
+	for {
+		var block *pem.Block
+		if block, b = pem.Decode(b); block == nil {
+			break
+		}
+		switch {
+		case block.Type == "CERTIFICATE":
+			certPEM = append(certPEM, pem.EncodeToMemory(block)...)
+		case block.Type == "ENCRYPTED PRIVATE KEY":
+			return nil, nil, errors.New("PKCS #8 encrypted private keys are not supported, convert the key with openssl rsa -traditional")
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			// Splunk encrypts private keys in the legacy PEM format, which is deprecated as insecure.
+			if x509.IsEncryptedPEMBlock(block) { //nolint:staticcheck
+				der, err := x509.DecryptPEMBlock(block, []byte(password)) //nolint:staticcheck
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to decrypt the private key with sslPassword: %w", err)
+				}
+				block = &pem.Block{Type: block.Type, Bytes: der}
+			}
+			keyPEM = pem.EncodeToMemory(block)
+		}
+	}
+	if certPEM == nil || keyPEM == nil {
+		return nil, nil, errors.New("the file does not hold a certificate and its private key")
+	}
+	return certPEM, keyPEM, nil
+}
+
+// forConfiguredInput returns the consumer of the log records read by an input, routed according to its _TCP_ROUTING
+// and _SYSLOG_ROUTING keys. Groups that outputs.conf does not declare are ignored.
+func (r *router) forConfiguredInput(input conf.Input, logger *zap.Logger) consumer.Logs {
+	exporters, unknown := r.inputExporters(input)
+	if len(unknown) > 0 {
+		logger.Warn("Ignoring output groups not declared by outputs.conf",
+			zap.String("app", input.Configuration.Stanza.App),
+			zap.String("input", input.Configuration.Stanza.Name),
+			zap.Strings("groups", unknown))
+	}
+	return r.forInput(input.Configuration.Stanza.Name, exporters)
+}
+
+// inputExporters returns the exporters receiving the events of an input that no route matches, as set by its
+// _TCP_ROUTING and _SYSLOG_ROUTING keys listing groups of outputs.conf, * standing for all tcpout groups.
+// As in Splunk, syslog routing adds to tcp routing: the default tcpout groups are kept if _TCP_ROUTING is not set.
+// The function returns nil if the input sets neither, and the groups that are not declared.
+func (r *router) inputExporters(input conf.Input) ([]string, []string) {
+	var exporters, unknown []string
+	tcpRouted := false
+	for _, routing := range []struct {
+		key    string
+		prefix string
+	}{
+		{"_TCP_ROUTING", tcpoutPrefix},
+		{"_SYSLOG_ROUTING", syslogPrefix},
+	} {
+		p := input.Configuration.Stanza.Params.Get(routing.key)
+		if p == nil || !slices.ContainsFunc(r.names(), func(name string) bool { return strings.HasPrefix(name, routing.prefix) }) {
+			// The key only applies when outputs.conf declares groups, as TAs may set it for other deployments.
+			continue
+		}
+		if routing.prefix == tcpoutPrefix {
+			tcpRouted = true
+		}
+		for group := range strings.SplitSeq(p.Value, ",") {
+			group = strings.TrimSpace(group)
+			switch {
+			case group == "":
+			case group == "*" && routing.prefix == tcpoutPrefix:
+				for _, name := range r.names() {
+					if strings.HasPrefix(name, tcpoutPrefix) {
+						exporters = append(exporters, name)
+					}
+				}
+			default:
+				if _, ok := r.exporters[routing.prefix+group]; ok {
+					exporters = append(exporters, routing.prefix+group)
+				} else {
+					unknown = append(unknown, routing.prefix+group)
+				}
+			}
+		}
+	}
+	if exporters != nil && !tcpRouted {
+		var defaults []string
+		for _, name := range r.defaultExporters {
+			if strings.HasPrefix(name, tcpoutPrefix) {
+				defaults = append(defaults, name)
+			}
+		}
+		exporters = append(defaults, exporters...)
+	}
+	return exporters, unknown
+}
@@ -0,0 +1,176 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/exporter"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/splunk/tarunner/internal/conf"
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/exporter/s2sexporter/s2stest"
+	"github.com/splunk/tarunner/internal/splunksecret"
+)
+
+func TestWithOutputs(t *testing.T) {
+	outputs := &conf.Outputs{
+		DefaultGroups: []string{"primary"},
+		TCPOut: []conf.TCPOutGroup{
+			{Name: "primary", Servers: []string{"idx1:9997"}, UseACK: true, SSL: conf.SSL{UseSSL: "true", SSLCommonNameToCheck: "idx.example.com"}},
+			{Name: "archive", Servers: []string{"archive:9997"}},
+		},
+		Syslog: []conf.SyslogGroup{
+			{Name: "siem", Server: "siem:514", Type: "tcp", SyslogSourceType: "sourcetype::syslog"},
+		},
+		HTTPOut: &conf.HTTPOut{URI: "https://hec:8088", Token: "foo"},
+	}
+	core, logs := observer.New(zap.WarnLevel)
+	logger := zap.New(core)
+	cfg, err := withOutputs(&config.Config{Exporters: map[string]config.Exporter{
+		"ops": {Type: "otlp_http", Endpoint: "http://otel:4318"},
+	}}, outputs, logger)
+	require.NoError(t, err)
+	assert.Len(t, cfg.Exporters, 5)
+	primary := cfg.Exporters["tcpout:primary"]
+	assert.Equal(t, "splunk_s2s", primary.Type)
+	assert.Equal(t, []string{"idx1:9997"}, primary.Servers)
+	assert.True(t, primary.UseACK)
+	assert.True(t, primary.UseTLS)
+	assert.True(t, primary.TLS.InsecureSkipVerify)
+	assert.Equal(t, "idx.example.com", primary.TLS.ServerName)
+	assert.False(t, cfg.Exporters["tcpout:archive"].UseTLS)
+	assert.Equal(t, configtls.ClientConfig{}, cfg.Exporters["tcpout:archive"].TLS)
+	httpout := cfg.Exporters["httpout"]
+	assert.Equal(t, "splunk_hec", httpout.Type)
+	assert.Equal(t, "https://hec:8088/services/collector", httpout.Endpoint)
+	assert.Equal(t, "foo", string(httpout.Token))
+	assert.Equal(t, config.Exporter{
+		Type:             "syslog",
+		Endpoint:         "siem:514",
+		Protocol:         "tcp",
+		Format:           "rfc3164",
+		SyslogSourceType: "syslog",
+	}, cfg.Exporters["syslog:siem"])
+	// syslog groups are not default groups unless listed by the defaultGroup key of [syslog].
+	assert.Equal(t, []string{"httpout", "ops", "tcpout:primary"}, cfg.DefaultExporters())
+	require.NoError(t, cfg.Validate())
+	// Both TLS outputs do not verify the certificate of servers, and each is warned about.
+	var insecure []string
+	for _, entry := range logs.FilterMessageSnippet("not verified").All() {
+		insecure = append(insecure, entry.ContextMap()["exporter"].(string))
+	}
+	assert.Equal(t, []string{"tcpout:primary", "httpout"}, insecure)
+
+	secretPath := filepath.Join(t.TempDir(), "splunk.secret")
+	require.NoError(t, os.WriteFile(secretPath, []byte(strings.Repeat("s", 255)), 0o600))
+	secret, err := splunksecret.Read(secretPath)
+	require.NoError(t, err)
+	password, err := secret.Encrypt("changeme")
+	require.NoError(t, err)
+	encrypted := &conf.Outputs{
+		TCPOut: []conf.TCPOutGroup{{Name: "primary", Servers: []string{"idx1:9997"}, SSL: conf.SSL{
+			UseSSL:      "true",
+			ClientCert:  filepath.Join("testdata", "tls", "client.pem"),
+			SSLPassword: password,
+		}}},
+	}
+	cfg, err = withOutputs(&config.Config{SplunkSecret: secretPath}, encrypted, zap.NewNop())
+	require.NoError(t, err)
+	tls := cfg.Exporters["tcpout:primary"].TLS
+	assert.Empty(t, tls.CertFile)
+	assert.NotContains(t, string(tls.KeyPem), "ENCRYPTED")
+	_, err = tls.LoadTLSConfig(context.Background())
+	require.NoError(t, err)
+	_, err = withOutputs(&config.Config{}, encrypted, zap.NewNop())
+	require.EqualError(t, err, "outputs.conf [tcpout:primary]: sslPassword: the value is encrypted: set splunk_secret to the path of the splunk.secret file")
+	encrypted.TCPOut[0].SSL.SSLPassword = "wrong"
+	_, err = withOutputs(&config.Config{}, encrypted, zap.NewNop())
+	require.ErrorContains(t, err, "outputs.conf [tcpout:primary]: clientCert: failed to decrypt the private key with sslPassword: ")
+
+	cfg = &config.Config{Exporter: config.Exporter{Type: "otlp_http", Endpoint: "http://otel:4318"}}
+	merged, err := withOutputs(cfg, &conf.Outputs{}, zap.NewNop())
+	require.NoError(t, err)
+	assert.Same(t, cfg, merged)
+}
+
+func TestInputExporters(t *testing.T) {
+	r := newRouter(map[string]exporter.Logs{
+		"tcpout:primary": nil,
+		"tcpout:archive": nil,
+		"syslog:siem":    nil,
+		"httpout":        nil,
+	}, nil, []string{"tcpout:primary"})
+	input := func(params ...conf.Param) conf.Input {
+		return conf.Input{Configuration: conf.Configuration{Stanza: conf.Stanza{Name: "monitor:///var/log", Params: params}}}
+	}
+
+	exporters, unknown := r.inputExporters(input())
+	assert.Nil(t, exporters)
+	assert.Nil(t, unknown)
+
+	exporters, unknown = r.inputExporters(input(conf.Param{Name: "_TCP_ROUTING", Value: "archive, missing"}))
+	assert.Equal(t, []string{"tcpout:archive"}, exporters)
+	assert.Equal(t, []string{"tcpout:missing"}, unknown)
+
+	exporters, unknown = r.inputExporters(input(conf.Param{Name: "_TCP_ROUTING", Value: "*"}))
+	assert.Equal(t, []string{"tcpout:archive", "tcpout:primary"}, exporters)
+	assert.Nil(t, unknown)
+
+	exporters, unknown = r.inputExporters(input(conf.Param{Name: "_TCP_ROUTING", Value: "primary"}, conf.Param{Name: "_SYSLOG_ROUTING", Value: "siem"}))
+	assert.Equal(t, []string{"tcpout:primary", "syslog:siem"}, exporters)
+	assert.Nil(t, unknown)
+
+	// Syslog routing adds to the default tcpout groups.
+	exporters, unknown = r.inputExporters(input(conf.Param{Name: "_SYSLOG_ROUTING", Value: "siem"}))
+	assert.Equal(t, []string{"tcpout:primary", "syslog:siem"}, exporters)
+	assert.Nil(t, unknown)
+
+	// Without tcpout groups, _TCP_ROUTING does not apply.
+	r = newRouter(map[string]exporter.Logs{"syslog:siem": nil}, nil, nil)
+	exporters, unknown = r.inputExporters(input(conf.Param{Name: "_TCP_ROUTING", Value: "primary"}))
+	assert.Nil(t, exporters)
+	assert.Nil(t, unknown)
+}
+
+func TestRunTAWithOutputs(t *testing.T) {
+	s, err := s2stest.NewServer()
+	require.NoError(t, err)
+	defer func() {
+		_ = s.Close()
+	}()
+	systemDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(systemDir, "outputs.conf"), []byte(`[tcpout]
+defaultGroup = primary
+
+[tcpout:primary]
+server = `+s.Addr()+`
+`), 0o600))
+	cancel, err := Run(filepath.Join("testdata", "ta"), &config.Config{}, WithSystemDir(systemDir))
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cancel(context.Background()))
+	}()
+
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		events := s.Events()
+		if assert.NotEmpty(tt, events) {
+			assert.Equal(tt, "sourcetype::_foo", events[0]["MetaData:Sourcetype"])
+		}
+	}, 2*time.Second, 10*time.Millisecond)
+
+	_, err = Run(filepath.Join("testdata", "ta"), &config.Config{})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	require.EqualError(t, err, "invalid configuration: no exporter is configured: declare one in tarunner.yaml, or outputs in outputs.conf")
+}
@@ -0,0 +1,142 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.uber.org/zap"
+
+	"github.com/splunk/tarunner/internal/conf"
+	"github.com/splunk/tarunner/internal/config"
+)
+
+// Reload re-reads the configuration files of the TAs and reconciles the running receivers with them.
+// Receivers of removed or changed inputs are stopped, receivers of added or changed inputs are started,
+// and receivers of unchanged inputs keep running. A change to the props or transforms of a TA changes all its inputs,
+// and a change to the metadata settings of cfg changes all inputs.
+// The exporters keep running: exporter, routing and storage settings changed in cfg or outputs.conf only apply after a restart.
+// If a receiver cannot be created, the function returns an error and the running receivers are left untouched.
+// If the receiver of a changed input cannot be started, the input keeps running with its previous configuration.
+func (c *Collector) Reload(cfg *config.Config) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	next, err := readTAs(c.baseDir, cfg, c.settings.localDir)
+	if err != nil {
+		return err
+	}
+	outputs, err := readOutputs(next, c.settings.systemDir)
+	if err != nil {
+		return err
+	}
+	if cfg, err = withOutputs(cfg, outputs, c.settings.logger); err != nil {
+		return err
+	}
+	if cfg, err = decryptSecrets(cfg); err != nil {
+		return err
+	}
+	if exporterChanged(c.cfg, cfg) {
+		c.settings.logger.Warn("Exporter, routing or storage settings changed, restart tarunner to apply them")
+	}
+	if c.settings.input != nil {
+		if err = selectInput(next, *c.settings.input); err != nil {
+			return err
+		}
+	}
+	if err = selectInputs(next, c.settings.only, c.settings.exclude); err != nil {
+		return err
+	}
+
+	type desiredInput struct {
+		input conf.Input
+		ta    *ta
+	}
+	desired := map[inputKey]desiredInput{}
+	for name, t := range next {
+		for _, input := range t.inputs {
+			if !isDisabled(input) {
+				desired[inputKey{app: name, stanza: input.Configuration.Stanza.Name}] = desiredInput{input: input, ta: t}
+			}
+		}
+	}
+
+	metadataChanged := !reflect.DeepEqual(c.cfg.Metadata, cfg.Metadata)
+	created := map[inputKey]inputReceiver{}
+	for key, d := range desired {
+		previous := c.tas[key.app]
+		taChanged := metadataChanged || previous == nil || !reflect.DeepEqual(d.ta.props, previous.props) || !reflect.DeepEqual(d.ta.transforms, previous.transforms)
+		if current, ok := c.receivers[key]; ok && !taChanged && reflect.DeepEqual(current.input, d.input) {
+			continue
+		}
+		l, err := createReceiver(d.ta.dir, c.router.forConfiguredInput(d.input, c.settings.logger), d.input, d.ta.transforms, d.ta.props, cfg.Metadata, c.settings)
+		if err != nil {
+			return fmt.Errorf("failed to create receiver %q of %q: %w", key.stanza, key.app, err)
+		}
+		created[key] = inputReceiver{receiver: l, input: d.input}
+	}
+
+	var errs []error
+	replaced := map[inputKey]conf.Input{}
+	for key, current := range c.receivers {
+		_, stillDesired := desired[key]
+		_, isReplaced := created[key]
+		if stillDesired && !isReplaced {
+			continue
+		}
+		if isReplaced {
+			replaced[key] = current.input
+		}
+		if err := current.receiver.Shutdown(context.Background()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop receiver %q of %q: %w", key.stanza, key.app, err))
+		}
+		delete(c.receivers, key)
+		c.settings.logger.Info("Stopped input", zap.String("app", key.app), zap.String("input", key.stanza))
+	}
+	for key, r := range created {
+		if err := r.receiver.Start(context.Background(), c.host); err != nil {
+			errs = append(errs, fmt.Errorf("failed to start receiver %q of %q: %w", key.stanza, key.app, err))
+			_ = r.receiver.Shutdown(context.Background())
+			if previous, ok := replaced[key]; ok {
+				if err := c.restartInput(key, previous); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+		c.receivers[key] = r
+		c.settings.logger.Info("Started input", zap.String("app", key.app), zap.String("input", key.stanza))
+	}
+	c.tas = next
+	c.cfg = cfg
+
+	return errors.Join(errs...)
+}
+
+// restartInput starts a receiver for input with the configuration of its TA before the reload,
+// so a changed input whose new receiver fails to start keeps running.
+func (c *Collector) restartInput(key inputKey, input conf.Input) error {
+	t := c.tas[key.app]
+	l, err := createReceiver(t.dir, c.router.forConfiguredInput(input, c.settings.logger), input, t.transforms, t.props, c.cfg.Metadata, c.settings)
+	if err == nil {
+		err = l.Start(context.Background(), c.host)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restart receiver %q of %q with its previous configuration: %w", key.stanza, key.app, err)
+	}
+	c.receivers[key] = inputReceiver{receiver: l, input: input}
+	c.settings.logger.Warn("Kept the previous configuration of input", zap.String("app", key.app), zap.String("input", key.stanza))
+	return nil
+}
+
+// exporterChanged returns true if the exporter, routing or storage settings differ between the two configurations.
+func exporterChanged(previous, next *config.Config) bool {
+	return !reflect.DeepEqual(previous.NamedExporters(), next.NamedExporters()) ||
+		!reflect.DeepEqual(previous.Routes, next.Routes) ||
+		!reflect.DeepEqual(previous.DefaultExporters(), next.DefaultExporters()) ||
+		!reflect.DeepEqual(previous.Storage, next.Storage)
+}
@@ -0,0 +1,133 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/splunk/tarunner/internal/config"
+)
+
+// router is the fan-out consumer between the receivers and the exporters.
+// It sends each log record to the exporters of the first route matching it, or to the default exporters.
+type router struct {
+	exporters        map[string]exporter.Logs
+	routes           []config.Route
+	defaultExporters []string
+}
+
+func newRouter(exporters map[string]exporter.Logs, routes []config.Route, defaultExporters []string) *router {
+	return &router{exporters: exporters, routes: routes, defaultExporters: defaultExporters}
+}
+
+// names returns the names of the exporters, sorted.
+func (r *router) names() []string {
+	names := make([]string, 0, len(r.exporters))
+	for name := range r.exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// forInput returns the consumer of the log records read by an input stanza.
+// Log records no route matches are sent to defaultExporters, or to the default exporters of the router if nil.
+func (r *router) forInput(stanza string, defaultExporters []string) consumer.Logs {
+	if defaultExporters == nil {
+		defaultExporters = r.defaultExporters
+	}
+	return &inputRouter{router: r, input: stanza, defaultExporters: defaultExporters}
+}
+
+// route returns the names of the exporters receiving a log record read by input, or defaultExporters if no route matches.
+func (r *router) route(lr plog.LogRecord, input string, defaultExporters []string) []string {
+	index := attribute(lr, "com.splunk.index")
+	sourceType := attribute(lr, "com.splunk.sourcetype")
+	for _, route := range r.routes {
+		if route.Match(index, sourceType, input) {
+			return route.Exporters
+		}
+	}
+	return defaultExporters
+}
+
+func attribute(lr plog.LogRecord, name string) string {
+	if v, ok := lr.Attributes().Get(name); ok {
+		return v.AsString()
+	}
+	return ""
+}
+
+// inputRouter routes the log records read by an input.
+type inputRouter struct {
+	*router
+	input            string
+	defaultExporters []string
+}
+
+func (r *inputRouter) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (r *inputRouter) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	if len(r.routes) == 0 && len(r.defaultExporters) == 1 {
+		return r.exporters[r.defaultExporters[0]].ConsumeLogs(ctx, ld)
+	}
+	batches := map[string]*batch{}
+	for _, rl := range ld.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, lr := range sl.LogRecords().All() {
+				for _, name := range r.route(lr, r.input, r.defaultExporters) {
+					b, ok := batches[name]
+					if !ok {
+						b = &batch{logs: plog.NewLogs()}
+						batches[name] = b
+					}
+					b.append(rl, sl, lr)
+				}
+			}
+		}
+	}
+	var errs []error
+	for _, name := range r.names() {
+		if b, ok := batches[name]; ok {
+			if err := r.exporters[name].ConsumeLogs(ctx, b.logs); err != nil {
+				errs = append(errs, fmt.Errorf("exporter %q: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// batch holds copies of the log records sent to an exporter, keeping their resource and scope.
+type batch struct {
+	logs plog.Logs
+	// rl and sl are the resource and scope of the last log record appended, and their copies.
+	rl, rlCopy plog.ResourceLogs
+	sl, slCopy plog.ScopeLogs
+}
+
+func (b *batch) append(rl plog.ResourceLogs, sl plog.ScopeLogs, lr plog.LogRecord) {
+	if b.logs.ResourceLogs().Len() == 0 || b.rl != rl {
+		b.rl = rl
+		b.rlCopy = b.logs.ResourceLogs().AppendEmpty()
+		rl.Resource().CopyTo(b.rlCopy.Resource())
+		b.rlCopy.SetSchemaUrl(rl.SchemaUrl())
+		b.sl = plog.ScopeLogs{}
+	}
+	if b.rlCopy.ScopeLogs().Len() == 0 || b.sl != sl {
+		b.sl = sl
+		b.slCopy = b.rlCopy.ScopeLogs().AppendEmpty()
+		sl.Scope().CopyTo(b.slCopy.Scope())
+		b.slCopy.SetSchemaUrl(sl.SchemaUrl())
+	}
+	lr.CopyTo(b.slCopy.LogRecords().AppendEmpty())
+}
@@ -0,0 +1,80 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/splunk/tarunner/internal/config"
+)
+
+type sinkExporter struct {
+	component.StartFunc
+	component.ShutdownFunc
+	*consumertest.LogsSink
+}
+
+func TestRouter(t *testing.T) {
+	security := &consumertest.LogsSink{}
+	ops := &consumertest.LogsSink{}
+	r := newRouter(map[string]exporter.Logs{
+		"security": sinkExporter{LogsSink: security},
+		"ops":      sinkExporter{LogsSink: ops},
+	}, []config.Route{
+		{SourceType: []string{"linux_secure", "auditd*"}, Exporters: []string{"security"}},
+		{Index: []string{"main"}, Exporters: []string{"security", "ops"}},
+		{Input: []string{"script://./bin/*"}, Index: []string{"os"}, Exporters: []string{"security"}},
+	}, []string{"ops"})
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("host.name", "web-1")
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, metadata := range []struct{ index, sourceType string }{
+		{"os", "linux_secure"},
+		{"os", "auditd:log"},
+		{"main", "syslog"},
+		{"os", "syslog"},
+		{"other", "syslog"},
+	} {
+		lr := lrs.AppendEmpty()
+		lr.Body().SetStr(metadata.index + "/" + metadata.sourceType)
+		lr.Attributes().PutStr("com.splunk.index", metadata.index)
+		lr.Attributes().PutStr("com.splunk.sourcetype", metadata.sourceType)
+	}
+	require.NoError(t, r.forInput("script://./bin/ps.sh", nil).ConsumeLogs(context.Background(), ld))
+	require.NoError(t, r.forInput("monitor:///var/log", nil).ConsumeLogs(context.Background(), ld))
+
+	bodies := func(sink *consumertest.LogsSink) []string {
+		var result []string
+		for _, logs := range sink.AllLogs() {
+			for _, rl := range logs.ResourceLogs().All() {
+				host, _ := rl.Resource().Attributes().Get("host.name")
+				assert.Equal(t, "web-1", host.Str())
+				for _, sl := range rl.ScopeLogs().All() {
+					for _, lr := range sl.LogRecords().All() {
+						result = append(result, lr.Body().Str())
+					}
+				}
+			}
+		}
+		return result
+	}
+	assert.Equal(t, []string{
+		"os/linux_secure", "os/auditd:log", "main/syslog", "os/syslog",
+		"os/linux_secure", "os/auditd:log", "main/syslog",
+	}, bodies(security))
+	assert.Equal(t, []string{
+		"main/syslog", "other/syslog",
+		"main/syslog", "os/syslog", "other/syslog",
+	}, bodies(ops))
+}
@@ -0,0 +1,48 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter"
+
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/exporter/s2sexporter"
+)
+
+func newS2SExporter(set component.TelemetrySettings, name string, eCfg config.Exporter, storage queueStorage) (exporter.Logs, error) {
+	f := s2sexporter.NewFactory()
+	cfg := f.CreateDefaultConfig().(*s2sexporter.Config)
+	cfg.Servers = eCfg.Servers
+	if len(cfg.Servers) == 0 {
+		cfg.Servers = []string{eCfg.Endpoint}
+	}
+	cfg.TLS = eCfg.TLS
+	cfg.UseTLS = eCfg.UseTLS
+	if eCfg.SendCookedData != nil {
+		cfg.SendCookedData = *eCfg.SendCookedData
+	}
+	cfg.UseACK = eCfg.UseACK
+	if eCfg.AutoLBFrequency != nil {
+		cfg.AutoLBFrequency = *eCfg.AutoLBFrequency
+	}
+	if eCfg.Timeout > 0 {
+		cfg.TimeoutConfig.Timeout = eCfg.Timeout
+	}
+	if err := eCfg.ApplyQueueAndRetry(&cfg.QueueConfig, &cfg.RetryConfig, storage.id, storage.maxBytes); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	e, err := f.CreateLogs(context.Background(), exporter.Settings{
+		ID:                component.NewIDWithName(f.Type(), name),
+		TelemetrySettings: set,
+	}, cfg)
+
+	return e, err
+}
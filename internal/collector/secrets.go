@@ -0,0 +1,77 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"errors"
+	"maps"
+	"slices"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/splunksecret"
+)
+
+// secrets decrypts the values Splunk encrypts, $7$ and $1$ values, with the splunk.secret file at path, read on first use.
+type secrets struct {
+	path   string
+	secret splunksecret.Secret
+}
+
+// decrypt returns value decrypted if it is encrypted, or value.
+func (s *secrets) decrypt(value string) (string, error) {
+	if !splunksecret.IsEncrypted(value) {
+		return value, nil
+	}
+	if s.secret == nil {
+		if s.path == "" {
+			return "", errors.New("the value is encrypted: set splunk_secret to the path of the splunk.secret file")
+		}
+		secret, err := splunksecret.Read(s.path)
+		if err != nil {
+			return "", err
+		}
+		s.secret = secret
+	}
+	return s.secret.Decrypt(value)
+}
+
+// decryptSecrets returns cfg with the encrypted tokens and header values of its exporters decrypted
+// with the splunk.secret file set by cfg. Problems are reported as FieldErrors.
+func decryptSecrets(cfg *config.Config) (*config.Config, error) {
+	s := &secrets{path: cfg.SplunkSecret}
+	var errs []error
+	decryptExporter := func(path string, e config.Exporter) config.Exporter {
+		token, err := s.decrypt(string(e.Token))
+		if err != nil {
+			errs = append(errs, &config.FieldError{Path: path + "token", Err: err})
+		}
+		e.Token = configopaque.String(token)
+		var headers configopaque.MapList
+		for name, value := range e.Headers.Iter {
+			decrypted, err := s.decrypt(string(value))
+			if err != nil {
+				errs = append(errs, &config.FieldError{Path: path + "headers." + name, Err: err})
+			}
+			headers.Set(name, configopaque.String(decrypted))
+		}
+		e.Headers = headers
+		return e
+	}
+
+	decrypted := *cfg
+	if len(cfg.Exporters) == 0 {
+		decrypted.Exporter = decryptExporter("", cfg.Exporter)
+	} else {
+		decrypted.Exporters = make(map[string]config.Exporter, len(cfg.Exporters))
+		for _, name := range slices.Sorted(maps.Keys(cfg.Exporters)) {
+			decrypted.Exporters[name] = decryptExporter("exporters."+name+".", cfg.Exporters[name])
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &decrypted, nil
+}
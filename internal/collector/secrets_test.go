@@ -0,0 +1,57 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configopaque"
+
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/splunksecret"
+)
+
+func TestDecryptSecrets(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "splunk.secret")
+	require.NoError(t, os.WriteFile(secretPath, []byte(strings.Repeat("s", 255)+"\n"), 0o600))
+	secret, err := splunksecret.Read(secretPath)
+	require.NoError(t, err)
+	token, err := secret.Encrypt("00000000-0000-0000-0000-000000000000")
+	require.NoError(t, err)
+	auth, err := secret.Encrypt("Bearer foo")
+	require.NoError(t, err)
+
+	var headers configopaque.MapList
+	headers.Set("Authorization", configopaque.String(auth))
+	headers.Set("X-Tenant", "security")
+	cfg := &config.Config{
+		SplunkSecret: secretPath,
+		Exporters: map[string]config.Exporter{
+			"hec":  {Type: "splunk_hec", Endpoint: "https://hec:8088", Token: configopaque.String(token)},
+			"otel": {Type: "otlp_http", Endpoint: "https://otel:4318", Headers: headers},
+		},
+	}
+	decrypted, err := decryptSecrets(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", string(decrypted.Exporters["hec"].Token))
+	value, _ := decrypted.Exporters["otel"].Headers.Get("Authorization")
+	assert.Equal(t, "Bearer foo", string(value))
+	value, _ = decrypted.Exporters["otel"].Headers.Get("X-Tenant")
+	assert.Equal(t, "security", string(value))
+	// cfg is left as is.
+	assert.Equal(t, token, string(cfg.Exporters["hec"].Token))
+
+	cfg.SplunkSecret = ""
+	_, err = decryptSecrets(cfg)
+	require.EqualError(t, err, "exporters.hec.token: the value is encrypted: set splunk_secret to the path of the splunk.secret file\n"+
+		"exporters.otel.headers.Authorization: the value is encrypted: set splunk_secret to the path of the splunk.secret file")
+
+	_, err = decryptSecrets(&config.Config{Exporter: config.Exporter{Type: "splunk_hec", Token: "$7$foo"}})
+	require.EqualError(t, err, "token: the value is encrypted: set splunk_secret to the path of the splunk.secret file")
+}
@@ -0,0 +1,168 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/trace"
+	nooptrace "go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+// Option customizes how the collector is built.
+type Option func(*settings)
+
+type settings struct {
+	logger         *zap.Logger
+	meterProvider  *sdkmetric.MeterProvider
+	metricReader   *sdkmetric.ManualReader
+	tracerProvider trace.TracerProvider
+	localDir       string
+	systemDir      string
+	console        *consoleSettings
+	// times is the number of times each script runs, or 0 to run scripts on their interval until the collector stops.
+	times int
+	// input, if set, is the only input run.
+	input *inputKey
+	// only and exclude are the shell patterns of the stanza names of the inputs to run, and not to run.
+	only    []string
+	exclude []string
+	// telemetryInterval is how often the metrics of tarunner are logged, or 0 not to log them.
+	// Only a started collector logs them, until it shuts down.
+	telemetryInterval time.Duration
+	// offsets is the storage extension keeping the offsets of monitor inputs, set when the collector is built.
+	// Like the extension, it only changes on restart.
+	offsets *component.ID
+	// passes counts the scripts that did not run their number of times yet.
+	passes sync.WaitGroup
+	// scriptErrs holds the errors of the last execution of the scripts that ran their number of times.
+	scriptErrs   []error
+	scriptErrsMu sync.Mutex
+	// done is closed when the run is complete. See Collector.Done.
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// WithLogger sets the logger used by the collector and all its components.
+func WithLogger(logger *zap.Logger) Option {
+	return func(s *settings) {
+		s.logger = logger
+	}
+}
+
+// WithLocalDir reads the local configuration files of the TA from dir instead of its local folder.
+// It is used to keep the configuration of the user apart from a packaged TA.
+func WithLocalDir(dir string) Option {
+	return func(s *settings) {
+		s.localDir = dir
+	}
+}
+
+// WithSystemDir reads outputs.conf from dir too, such as the etc/system/local folder of a forwarder.
+// Its settings take precedence over the outputs.conf files of the TAs.
+func WithSystemDir(dir string) Option {
+	return func(s *settings) {
+		s.systemDir = dir
+	}
+}
+
+// WithConsole replaces the exporter with a console writer printing log records to out, in the json or raw format.
+// If maxEvents is positive, the run is complete once maxEvents log records are printed, and further records are dropped.
+func WithConsole(out io.Writer, format string, maxEvents int) Option {
+	return func(s *settings) {
+		s.console = &consoleSettings{out: out, format: format, maxEvents: maxEvents}
+	}
+}
+
+// WithOnce runs each script a single time instead of on its interval.
+// The run is complete once all scripts ran.
+func WithOnce() Option {
+	return WithTimes(1)
+}
+
+// WithTimes runs each script n times on its interval instead of until the collector stops.
+// The run is complete once all scripts ran n times.
+func WithTimes(n int) Option {
+	return func(s *settings) {
+		s.times = n
+	}
+}
+
+// WithInput only runs the input stanza of the given app, or of any app if app is empty.
+// The input runs even if it is disabled.
+func WithInput(app string, stanza string) Option {
+	return func(s *settings) {
+		s.input = &inputKey{app: app, stanza: stanza}
+	}
+}
+
+// WithSelection only runs the inputs whose stanza name matches one of the only patterns, if any,
+// and none of the exclude patterns. Patterns are shell patterns, such as script://./bin/*.sh.
+func WithSelection(only []string, exclude []string) Option {
+	return func(s *settings) {
+		s.only = only
+		s.exclude = exclude
+	}
+}
+
+func newSettings(opts []Option) (*settings, error) {
+	s := &settings{
+		tracerProvider: nooptrace.NewTracerProvider(),
+		done:           make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.logger == nil {
+		logger, err := zap.NewProduction()
+		if err != nil {
+			return nil, err
+		}
+		s.logger = logger
+	}
+	// Component metrics are kept in memory, and read to report on data loss.
+	s.metricReader = sdkmetric.NewManualReader()
+	readers := []sdkmetric.Option{sdkmetric.WithReader(s.metricReader)}
+	if s.telemetryInterval > 0 {
+		readers = append(readers, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(&logExporter{logger: s.logger}, sdkmetric.WithInterval(s.telemetryInterval))))
+	}
+	s.meterProvider = sdkmetric.NewMeterProvider(readers...)
+	return s, nil
+}
+
+func (s *settings) telemetrySettings() component.TelemetrySettings {
+	return component.TelemetrySettings{
+		Logger:         s.logger,
+		MeterProvider:  s.meterProvider,
+		TracerProvider: s.tracerProvider,
+	}
+}
+
+// complete marks the run as complete.
+func (s *settings) complete() {
+	s.doneOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// scriptRan returns the function called by a script input once it ran its number of times.
+func (s *settings) scriptRan() func(error) {
+	if s.times <= 0 {
+		return nil
+	}
+	s.passes.Add(1)
+	return func(err error) {
+		if err != nil {
+			s.scriptErrsMu.Lock()
+			s.scriptErrs = append(s.scriptErrs, err)
+			s.scriptErrsMu.Unlock()
+		}
+		s.passes.Done()
+	}
+}
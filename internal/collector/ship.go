@@ -0,0 +1,134 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/exporter/fileexporter"
+)
+
+// Shipper sends the spool files written by a file exporter with another exporter.
+type Shipper struct {
+	logger *zap.Logger
+	name   string
+	e      exporter.Logs
+}
+
+// NewShipper creates the exporter of cfg named name to ship spool files with.
+// If name is empty, the exporter is the only default exporter of cfg that is not a file exporter.
+func NewShipper(cfg *config.Config, name string, opts ...Option) (*Shipper, error) {
+	s, err := newSettings(opts)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := readOutputs(nil, s.systemDir)
+	if err != nil {
+		return nil, err
+	}
+	if cfg, err = withOutputs(cfg, outputs, s.logger); err != nil {
+		return nil, err
+	}
+	if cfg, err = decryptSecrets(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Type == "" && len(cfg.Exporters) == 0 {
+		return nil, errors.New("no exporter is configured: declare one in tarunner.yaml, or outputs in outputs.conf")
+	}
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if name, err = shipExporter(cfg, name); err != nil {
+		return nil, err
+	}
+	// Files are sent synchronously, to be deleted once sent.
+	eCfg := cfg.NamedExporters()[name]
+	eCfg.SendingQueue = map[string]any{"enabled": false}
+	eCfg.Batch = nil
+	e, err := newExporter(s.telemetrySettings(), name, eCfg, queueStorage{})
+	if err != nil {
+		return nil, fmt.Errorf("exporter %q: %w", name, err)
+	}
+	return &Shipper{logger: s.logger, name: name, e: e}, nil
+}
+
+// Ship sends the spool files of dir, oldest first. Each file is deleted once sent, or moved to archiveDir if set.
+// Shipping stops at the first file that fails to send. The function returns the number of files shipped.
+// A Shipper ships once.
+func (s *Shipper) Ship(ctx context.Context, dir string, archiveDir string) (int, error) {
+	files, err := fileexporter.Files(dir)
+	if err != nil {
+		return 0, err
+	}
+	if archiveDir != "" {
+		if err = os.MkdirAll(archiveDir, 0o700); err != nil {
+			return 0, err
+		}
+	}
+	if err = s.e.Start(ctx, host{}); err != nil {
+		return 0, fmt.Errorf("exporter %q: %w", s.name, err)
+	}
+	defer func() {
+		_ = s.e.Shutdown(context.Background())
+	}()
+
+	shipped := 0
+	for _, path := range files {
+		if err = fileexporter.ReadFile(path, func(ld plog.Logs) error {
+			return s.e.ConsumeLogs(ctx, ld)
+		}); err != nil {
+			return shipped, fmt.Errorf("%s: %w", path, err)
+		}
+		if archiveDir != "" {
+			err = os.Rename(path, filepath.Join(archiveDir, filepath.Base(path)))
+		} else {
+			err = os.Remove(path)
+		}
+		if err != nil {
+			return shipped, err
+		}
+		shipped++
+		s.logger.Info("Shipped spool file", zap.String("file", path), zap.String("exporter", s.name))
+	}
+	return shipped, nil
+}
+
+// shipExporter returns the name of the exporter of cfg to ship spool files with.
+func shipExporter(cfg *config.Config, name string) (string, error) {
+	exporters := cfg.NamedExporters()
+	if name != "" {
+		e, ok := exporters[name]
+		if !ok {
+			return "", fmt.Errorf("unknown exporter %q", name)
+		}
+		if e.Type == "file" {
+			return "", fmt.Errorf("exporter %q writes spool files, select an exporter sending them", name)
+		}
+		return name, nil
+	}
+	var candidates []string
+	for _, n := range cfg.DefaultExporters() {
+		if exporters[n].Type != "file" {
+			candidates = append(candidates, n)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return "", errors.New("no exporter to ship with: declare one sending to HEC, OTLP or indexers")
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("several exporters may ship spool files, select one of %s", strings.Join(candidates, ", "))
+	}
+}
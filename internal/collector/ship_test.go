@@ -0,0 +1,66 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/exporter/fileexporter"
+	"github.com/splunk/tarunner/internal/exporter/s2sexporter/s2stest"
+)
+
+func TestShip(t *testing.T) {
+	spool := t.TempDir()
+	fileCfg := config.Exporter{Type: "file", Directory: spool}
+	e, err := newFileExporter(componenttest.NewNopTelemetrySettings(), "spool", fileCfg, queueStorage{})
+	require.NoError(t, err)
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	ld := plog.NewLogs()
+	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Body().SetStr("hello")
+	lr.Attributes().PutStr("com.splunk.sourcetype", "app")
+	require.NoError(t, e.ConsumeLogs(context.Background(), ld))
+	require.NoError(t, e.Shutdown(context.Background()))
+
+	s, err := s2stest.NewServer()
+	require.NoError(t, err)
+	defer func() {
+		_ = s.Close()
+	}()
+	cfg := &config.Config{Exporters: map[string]config.Exporter{
+		"spool": fileCfg,
+		"fwd":   {Type: "splunk_s2s", Endpoint: s.Addr(), UseACK: true},
+	}}
+	archive := filepath.Join(t.TempDir(), "archive")
+	shipper, err := NewShipper(cfg, "")
+	require.NoError(t, err)
+	shipped, err := shipper.Ship(context.Background(), spool, archive)
+	require.NoError(t, err)
+	assert.Equal(t, 1, shipped)
+	events := s.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "hello", events[0]["_raw"])
+	assert.Equal(t, "sourcetype::app", events[0]["MetaData:Sourcetype"])
+	files, err := fileexporter.Files(spool)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	archived, err := os.ReadDir(archive)
+	require.NoError(t, err)
+	assert.Len(t, archived, 1)
+
+	_, err = NewShipper(cfg, "spool")
+	require.EqualError(t, err, `exporter "spool" writes spool files, select an exporter sending them`)
+	cfg.Exporters["ops"] = config.Exporter{Type: "otlp_http", Endpoint: "http://otel:4318"}
+	_, err = NewShipper(cfg, "")
+	require.EqualError(t, err, "several exporters may ship spool files, select one of fwd, ops")
+}
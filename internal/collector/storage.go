@@ -0,0 +1,111 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/filestorage"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/splunk/tarunner/internal/config"
+)
+
+// storageID is the ID of the storage extension holding the persistent queues of exporters.
+var storageID = component.MustNewID("file_storage")
+
+// queueStorage is the storage of the persistent queues given to an exporter.
+type queueStorage struct {
+	id *component.ID
+	// maxBytes is the size of the persistent queue of the exporter, in bytes.
+	maxBytes int64
+}
+
+// newQueueStorage returns the storage of the persistent queues of the exporters of cfg:
+// the size of the storage is shared equally between them.
+func newQueueStorage(cfg *config.Config) queueStorage {
+	if cfg.Storage == nil {
+		return queueStorage{}
+	}
+	persistent := 0
+	for _, e := range cfg.NamedExporters() {
+		if e.Persistent() {
+			persistent++
+		}
+	}
+	if persistent == 0 {
+		return queueStorage{}
+	}
+	maxSizeMiB := cfg.Storage.MaxSizeMiB
+	if maxSizeMiB == 0 {
+		maxSizeMiB = config.DefaultStorageMaxSizeMiB
+	}
+	return queueStorage{id: &storageID, maxBytes: (maxSizeMiB << 20) / int64(persistent)}
+}
+
+// offsetStorage returns the ID of the storage extension keeping the offsets of the files read by monitor inputs,
+// or nil if storage.directory is not set or if events are printed instead of exported.
+func offsetStorage(cfg *config.Config, s *settings) *component.ID {
+	if s.console != nil || cfg.Storage == nil || cfg.Storage.Directory == "" {
+		return nil
+	}
+	return &storageID
+}
+
+// newStorage creates the file storage extension keeping its files in the storage directory.
+// Files are compacted on start, and once their queue drained after growing.
+func newStorage(set component.TelemetrySettings, cfg config.Storage) (extension.Extension, error) {
+	f := filestorage.NewFactory()
+	fCfg := f.CreateDefaultConfig().(*filestorage.Config)
+	fCfg.Directory = cfg.Directory
+	fCfg.CreateDirectory = true
+	fCfg.Compaction.Directory = cfg.Directory
+	fCfg.Compaction.OnStart = true
+	fCfg.Compaction.OnRebound = true
+	fCfg.Compaction.CleanupOnStart = true
+	if err := fCfg.Validate(); err != nil {
+		return nil, err
+	}
+	return f.Create(context.Background(), extension.Settings{
+		ID:                storageID,
+		TelemetrySettings: set,
+	}, fCfg)
+}
+
+// registerDiskUsage reports the size of the files of the storage directory as the tarunner_storage_disk_usage gauge.
+func registerDiskUsage(meter metric.Meter, dir string) error {
+	_, err := meter.Int64ObservableGauge("tarunner_storage_disk_usage",
+		metric.WithDescription("Size of the files of the persistent queues."),
+		metric.WithUnit("By"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			size, err := diskUsage(dir)
+			if err != nil {
+				return err
+			}
+			o.Observe(size)
+			return nil
+		}))
+	return err
+}
+
+// diskUsage returns the total size of the files of dir.
+func diskUsage(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,49 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter"
+
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/exporter/syslogexporter"
+)
+
+func newSyslogExporter(set component.TelemetrySettings, name string, eCfg config.Exporter, storage queueStorage) (exporter.Logs, error) {
+	f := syslogexporter.NewFactory()
+	cfg := f.CreateDefaultConfig().(*syslogexporter.Config)
+	cfg.Endpoint = eCfg.Endpoint
+	cfg.TLS = eCfg.TLS
+	if eCfg.Protocol != "" {
+		cfg.Protocol = eCfg.Protocol
+	}
+	if eCfg.Format != "" {
+		cfg.Format = eCfg.Format
+	}
+	if eCfg.Priority != "" {
+		cfg.Priority = eCfg.Priority
+	}
+	cfg.TimestampFormat = eCfg.TimestampFormat
+	cfg.SyslogSourceType = eCfg.SyslogSourceType
+	if eCfg.MaxEventSize > 0 {
+		cfg.MaxEventSize = eCfg.MaxEventSize
+	}
+	if eCfg.Timeout > 0 {
+		cfg.TimeoutConfig.Timeout = eCfg.Timeout
+	}
+	if err := eCfg.ApplyQueueAndRetry(&cfg.QueueConfig, &cfg.RetryConfig, storage.id, storage.maxBytes); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return f.CreateLogs(context.Background(), exporter.Settings{
+		ID:                component.NewIDWithName(f.Type(), name),
+		TelemetrySettings: set,
+	}, cfg)
+}
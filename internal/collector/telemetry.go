@@ -0,0 +1,91 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"context"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+)
+
+// meterName is the name of the meter of the metrics of tarunner itself, such as tarunner_storage_disk_usage.
+const meterName = "github.com/splunk/tarunner"
+
+// telemetryInterval is how often the metrics of tarunner are logged.
+const telemetryInterval = time.Minute
+
+// droppedLogRecordsMetrics are the exporter metrics counting log records that could not be delivered.
+var droppedLogRecordsMetrics = map[string]bool{
+	"otelcol_exporter_send_failed_log_records":    true,
+	"otelcol_exporter_enqueue_failed_log_records": true,
+}
+
+// droppedLogRecords returns the number of log records the exporter failed to deliver.
+func (s *settings) droppedLogRecords(ctx context.Context) (int64, error) {
+	var rm metricdata.ResourceMetrics
+	if err := s.metricReader.Collect(ctx, &rm); err != nil {
+		return 0, err
+	}
+	var dropped int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if !droppedLogRecordsMetrics[m.Name] {
+				continue
+			}
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
+				for _, dp := range sum.DataPoints {
+					dropped += dp.Value
+				}
+			}
+		}
+	}
+	return dropped, nil
+}
+
+// withTelemetryInterval logs the metrics of tarunner every interval.
+func withTelemetryInterval(interval time.Duration) Option {
+	return func(s *settings) {
+		s.telemetryInterval = interval
+	}
+}
+
+// logExporter logs the gauges of tarunner, leaving out the metrics of the collector components.
+type logExporter struct {
+	logger *zap.Logger
+}
+
+func (e *logExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(kind)
+}
+
+func (e *logExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+func (e *logExporter) Export(_ context.Context, rm *metricdata.ResourceMetrics) error {
+	for _, sm := range rm.ScopeMetrics {
+		if sm.Scope.Name != meterName {
+			continue
+		}
+		for _, m := range sm.Metrics {
+			if gauge, ok := m.Data.(metricdata.Gauge[int64]); ok {
+				for _, dp := range gauge.DataPoints {
+					e.logger.Info("Telemetry", zap.String("metric", m.Name), zap.Int64("value", dp.Value), zap.String("unit", m.Unit))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (e *logExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (e *logExporter) Shutdown(context.Context) error {
+	return nil
+}
@@ -0,0 +1,3 @@
+#!/bin/sh
+echo unscheduled
+exit 2
@@ -50,34 +50,47 @@ type Param struct {
 }
 
 func ReadInput(payload []byte) ([]Input, error) {
+	stanzas, err := ReadStanzas(payload)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Input, len(stanzas))
+	for i, stanza := range stanzas {
+		stanza.App = appName
+		result[i] = Input{
+			Configuration: Configuration{
+				Stanza: stanza,
+			},
+		}
+	}
+	return result, nil
+}
+
+// ReadStanzas reads the stanzas of a configuration file with all their keys, in the order of the file.
+func ReadStanzas(payload []byte) ([]Stanza, error) {
 	f, err := ini.Load(payload)
 	if err != nil {
 		return nil, err
 	}
-	result := make([]Input, len(f.Sections())-1)
+	result := make([]Stanza, len(f.Sections())-1)
 	s := 0
 	for _, section := range f.Sections() {
 		if section.Name() == ini.DefaultSection {
-			continue // disregard default section. We need a stanza per input.
+			continue // disregard default section, which holds no stanza.
 		}
-		i := Input{
-			Configuration: Configuration{
-				Stanza: Stanza{
-					Name:   section.Name(),
-					App:    appName,
-					Params: make([]Param, len(section.Keys())),
-				},
-			},
+		stanza := Stanza{
+			Name:   section.Name(),
+			Params: make([]Param, len(section.Keys())),
 		}
 
 		for keyIndex, key := range section.Keys() {
-			i.Configuration.Stanza.Params[keyIndex] = Param{
+			stanza.Params[keyIndex] = Param{
 				Name:  key.Name(),
 				Value: key.Value(),
 			}
 		}
 
-		result[s] = i
+		result[s] = stanza
 		s++
 	}
 
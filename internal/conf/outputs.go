@@ -0,0 +1,244 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package conf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// Outputs holds the settings of outputs.conf: the tcpout groups forwarding events to indexers,
+// the syslog groups sending events to syslog servers, and the httpout stanza sending events over HEC.
+type Outputs struct {
+	// DefaultGroups are the tcpout groups receiving the events of inputs without _TCP_ROUTING,
+	// set by the defaultGroup key of the [tcpout] stanza.
+	DefaultGroups []string
+	// TCPOut lists the enabled [tcpout:<group>] stanzas, in the order of the files.
+	TCPOut []TCPOutGroup
+	// SyslogDefaultGroups are the syslog groups receiving the events of inputs without _SYSLOG_ROUTING,
+	// set by the defaultGroup key of the [syslog] stanza.
+	SyslogDefaultGroups []string
+	// Syslog lists the enabled [syslog:<group>] stanzas, in the order of the files.
+	Syslog []SyslogGroup
+	// HTTPOut is set by an enabled [httpout] stanza.
+	HTTPOut *HTTPOut
+}
+
+// TCPOutGroup is a [tcpout:<group>] stanza. Keys not set in the stanza are read from the [tcpout] stanza.
+type TCPOutGroup struct {
+	Name    string
+	Servers []string
+	// SendCookedData and AutoLBFrequency are nil if not set.
+	SendCookedData  *bool
+	UseACK          bool
+	AutoLBFrequency *time.Duration
+	SSL             SSL
+}
+
+// SyslogGroup is a [syslog:<group>] stanza. Keys not set in the stanza are read from the [syslog] stanza.
+type SyslogGroup struct {
+	Name   string
+	Server string
+	// Type is udp or tcp.
+	Type             string
+	Priority         string
+	TimestampFormat  string
+	SyslogSourceType string
+	// MaxEventSize is 0 if not set.
+	MaxEventSize int
+}
+
+// HTTPOut is the [httpout] stanza.
+type HTTPOut struct {
+	URI   string
+	Token string
+	SSL   SSL
+}
+
+// SSL holds the TLS settings of an output.
+type SSL struct {
+	// UseSSL is true, false, or legacy (the default) to only use TLS with a client certificate.
+	UseSSL               string
+	ClientCert           string
+	SSLPassword          string
+	SSLRootCAPath        string
+	SSLCommonNameToCheck string
+	// SSLVerifyServerCert is false by default, as in Splunk.
+	SSLVerifyServerCert bool
+}
+
+// Enabled returns true if connections use TLS.
+func (s SSL) Enabled() bool {
+	if strings.EqualFold(s.UseSSL, "legacy") || s.UseSSL == "" {
+		return s.ClientCert != ""
+	}
+	return isTrue(s.UseSSL)
+}
+
+// ReadOutputs reads outputs.conf files. Keys of later files take precedence over keys of the same stanza of earlier files.
+func ReadOutputs(payloads ...[]byte) (*Outputs, error) {
+	if len(payloads) == 0 {
+		return &Outputs{}, nil
+	}
+	sources := make([]any, len(payloads))
+	for i, p := range payloads {
+		sources[i] = p
+	}
+	f, err := ini.Load(sources[0], sources[1:]...)
+	if err != nil {
+		return nil, err
+	}
+	o := &Outputs{}
+	global := f.Section("tcpout")
+	if groups := global.Key("defaultGroup").String(); groups != "" {
+		o.DefaultGroups = splitList(groups)
+	}
+	syslog := f.Section("syslog")
+	if groups := syslog.Key("defaultGroup").String(); groups != "" {
+		o.SyslogDefaultGroups = splitList(groups)
+	}
+	for _, section := range f.Sections() {
+		name, isGroup := strings.CutPrefix(section.Name(), "tcpout:")
+		syslogName, isSyslogGroup := strings.CutPrefix(section.Name(), "syslog:")
+		switch {
+		case isSyslogGroup:
+			if disabled(section) {
+				continue
+			}
+			g, err := readSyslogGroup(syslogName, section, syslog)
+			if err != nil {
+				return nil, fmt.Errorf("[%s]: %w", section.Name(), err)
+			}
+			o.Syslog = append(o.Syslog, g)
+		case isGroup:
+			if disabled(section) {
+				continue
+			}
+			g, err := readTCPOutGroup(name, section, global)
+			if err != nil {
+				return nil, fmt.Errorf("[%s]: %w", section.Name(), err)
+			}
+			o.TCPOut = append(o.TCPOut, g)
+		case section.Name() == "httpout":
+			if disabled(section) {
+				continue
+			}
+			o.HTTPOut = &HTTPOut{
+				URI:   section.Key("uri").String(),
+				Token: section.Key("httpEventCollectorToken").String(),
+				SSL:   readSSL(section, nil),
+			}
+		}
+	}
+	return o, nil
+}
+
+func readTCPOutGroup(name string, section *ini.Section, global *ini.Section) (TCPOutGroup, error) {
+	g := TCPOutGroup{
+		Name:    name,
+		Servers: splitList(section.Key("server").String()),
+		SSL:     readSSL(section, global),
+	}
+	key := func(name string) *ini.Key {
+		if section.HasKey(name) {
+			return section.Key(name)
+		}
+		return global.Key(name)
+	}
+	if k := key("sendCookedData"); k.String() != "" {
+		cooked, err := k.Bool()
+		if err != nil {
+			return g, fmt.Errorf("sendCookedData: %w", err)
+		}
+		g.SendCookedData = &cooked
+	}
+	if k := key("useACK"); k.String() != "" {
+		useACK, err := k.Bool()
+		if err != nil {
+			return g, fmt.Errorf("useACK: %w", err)
+		}
+		g.UseACK = useACK
+	}
+	if k := key("autoLBFrequency"); k.String() != "" {
+		seconds, err := k.Int()
+		if err != nil {
+			return g, fmt.Errorf("autoLBFrequency: %w", err)
+		}
+		frequency := time.Duration(seconds) * time.Second
+		g.AutoLBFrequency = &frequency
+	}
+	return g, nil
+}
+
+func readSyslogGroup(name string, section *ini.Section, global *ini.Section) (SyslogGroup, error) {
+	value := func(name string) string {
+		if section.HasKey(name) {
+			return section.Key(name).String()
+		}
+		return global.Key(name).String()
+	}
+	g := SyslogGroup{
+		Name:             name,
+		Server:           value("server"),
+		Type:             value("type"),
+		Priority:         value("priority"),
+		TimestampFormat:  value("timestampformat"),
+		SyslogSourceType: value("syslogSourceType"),
+	}
+	if size := value("maxEventSize"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			return g, fmt.Errorf("maxEventSize: %w", err)
+		}
+		g.MaxEventSize = n
+	}
+	return g, nil
+}
+
+// readSSL reads the TLS settings of a stanza, defaulting to the settings of the global stanza if not nil.
+func readSSL(section *ini.Section, global *ini.Section) SSL {
+	value := func(name string) string {
+		if section.HasKey(name) || global == nil {
+			return section.Key(name).String()
+		}
+		return global.Key(name).String()
+	}
+	return SSL{
+		UseSSL:               value("useSSL"),
+		ClientCert:           value("clientCert"),
+		SSLPassword:          value("sslPassword"),
+		SSLRootCAPath:        value("sslRootCAPath"),
+		SSLCommonNameToCheck: value("sslCommonNameToCheck"),
+		SSLVerifyServerCert:  isTrue(value("sslVerifyServerCert")),
+	}
+}
+
+// isTrue returns true if value is a true boolean of Splunk configuration files.
+func isTrue(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "1", "yes", "t", "y", "on":
+		return true
+	}
+	return false
+}
+
+// disabled returns true if the disabled key of a stanza is set to true.
+func disabled(section *ini.Section) bool {
+	return isTrue(section.Key("disabled").String())
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
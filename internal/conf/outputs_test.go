@@ -0,0 +1,91 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package conf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOutputs(t *testing.T) {
+	outputs, err := ReadOutputs([]byte(`[tcpout]
+defaultGroup = primary
+useACK = true
+autoLBFrequency = 30
+sslVerifyServerCert = true
+
+[tcpout:primary]
+server = idx1:9997, idx2:9997
+clientCert = /certs/client.pem
+
+[tcpout:archive]
+server = archive:9997
+useACK = false
+sendCookedData = false
+
+[tcpout:old]
+server = old:9997
+disabled = true
+
+[httpout]
+uri = https://hec:8088
+httpEventCollectorToken = foo
+
+[syslog]
+defaultGroup = siem
+type = tcp
+
+[syslog:siem]
+server = siem:514
+priority = <34>
+timestampformat = %b %e %H:%M:%S
+syslogSourceType = sourcetype::syslog
+maxEventSize = 4096
+`), []byte(`[tcpout:archive]
+server = archive2:9997
+
+[httpout]
+httpEventCollectorToken = bar
+`))
+	require.NoError(t, err)
+	frequency := 30 * time.Second
+	cooked := false
+	assert.Equal(t, []string{"primary"}, outputs.DefaultGroups)
+	assert.Equal(t, []TCPOutGroup{{
+		Name:            "primary",
+		Servers:         []string{"idx1:9997", "idx2:9997"},
+		UseACK:          true,
+		AutoLBFrequency: &frequency,
+		SSL:             SSL{ClientCert: "/certs/client.pem", SSLVerifyServerCert: true},
+	}, {
+		Name:            "archive",
+		Servers:         []string{"archive2:9997"},
+		SendCookedData:  &cooked,
+		AutoLBFrequency: &frequency,
+		SSL:             SSL{SSLVerifyServerCert: true},
+	}}, outputs.TCPOut)
+	assert.Equal(t, &HTTPOut{URI: "https://hec:8088", Token: "bar"}, outputs.HTTPOut)
+	assert.Equal(t, []string{"siem"}, outputs.SyslogDefaultGroups)
+	assert.Equal(t, []SyslogGroup{{
+		Name:             "siem",
+		Server:           "siem:514",
+		Type:             "tcp",
+		Priority:         "<34>",
+		TimestampFormat:  "%b %e %H:%M:%S",
+		SyslogSourceType: "sourcetype::syslog",
+		MaxEventSize:     4096,
+	}}, outputs.Syslog)
+	assert.True(t, outputs.TCPOut[0].SSL.Enabled())
+	assert.False(t, outputs.TCPOut[1].SSL.Enabled())
+
+	_, err = ReadOutputs([]byte("[tcpout:primary]\nautoLBFrequency = soon\n"))
+	require.ErrorContains(t, err, "[tcpout:primary]: autoLBFrequency: ")
+
+	outputs, err = ReadOutputs()
+	require.NoError(t, err)
+	assert.Equal(t, &Outputs{}, outputs)
+}
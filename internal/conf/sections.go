@@ -0,0 +1,104 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package conf
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// Section is a stanza of a configuration file, with the line of the file where it and each of its keys are set.
+type Section struct {
+	Name     string
+	Line     int
+	Settings []Setting
+}
+
+// Setting is a key of a stanza.
+type Setting struct {
+	Key   string
+	Value string
+	Line  int
+}
+
+// ReadSections reads the stanzas of a configuration file, in the order of the file.
+// Values are read as by ReadStanzas; when a key is set several times in a stanza, the last line setting it is reported.
+func ReadSections(payload []byte) ([]Section, error) {
+	f, err := ini.Load(payload)
+	if err != nil {
+		return nil, err
+	}
+	sectionLines, keyLines := lines(payload)
+	var result []Section
+	for _, section := range f.Sections() {
+		if section.Name() == ini.DefaultSection {
+			continue // disregard default section, which holds no stanza.
+		}
+		s := Section{
+			Name: section.Name(),
+			Line: sectionLines[section.Name()],
+		}
+		for _, key := range section.Keys() {
+			s.Settings = append(s.Settings, Setting{
+				Key:   key.Name(),
+				Value: key.Value(),
+				Line:  keyLines[section.Name()][key.Name()],
+			})
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// lines returns the line numbers of the stanza headers and of the keys of each stanza of a configuration file.
+func lines(payload []byte) (map[string]int, map[string]map[string]int) {
+	sectionLines := map[string]int{}
+	keyLines := map[string]map[string]int{}
+	section := ini.DefaultSection
+	continued := false
+	scanner := bufio.NewScanner(bytes.NewReader(payload))
+	scanner.Buffer(nil, len(payload)+1)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case continued:
+			// The line continues the value of the previous line.
+			continued = strings.HasSuffix(line, `\`)
+		case line == "", line[0] == '#', line[0] == ';':
+		case line[0] == '[' && strings.HasSuffix(line, "]"):
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := sectionLines[section]; !ok {
+				sectionLines[section] = n
+			}
+		default:
+			i := strings.IndexAny(line, "=:")
+			if i < 0 {
+				continue
+			}
+			if keyLines[section] == nil {
+				keyLines[section] = map[string]int{}
+			}
+			keyLines[section][strings.TrimSpace(line[:i])] = n
+			continued = strings.HasSuffix(line, `\`)
+		}
+	}
+	return sectionLines, keyLines
+}
+
+// secretKeys are the parts of key names denoting secrets.
+var secretKeys = []string{"password", "passwd", "token", "secret", "pass4symmkey", "apikey", "api_key", "access_key", "private_key"}
+
+// IsSecret returns true if the key of a stanza holds a secret, such as a password or a token.
+func IsSecret(key string) bool {
+	lower := strings.ToLower(key)
+	for _, s := range secretKeys {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSections(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", "sections.conf"))
+	require.NoError(t, err)
+	res, err := ReadSections(b)
+	require.NoError(t, err)
+	require.Equal(t, []Section{
+		{
+			Name: "monitor:///var/log",
+			Line: 2,
+			Settings: []Setting{
+				{Key: "index", Value: "main", Line: 3},
+				{Key: "sourcetype", Value: "syslog", Line: 6},
+				{Key: "token", Value: "abc", Line: 7},
+			},
+		},
+		{
+			Name: "script://./bin/app.sh",
+			Line: 9,
+			Settings: []Setting{
+				{Key: "interval", Value: "30", Line: 11},
+			},
+		},
+	}, res)
+}
+
+func TestIsSecret(t *testing.T) {
+	for _, key := range []string{"token", "password", "sslPassword", "pass4SymmKey", "client_secret", "api_key"} {
+		assert.True(t, IsSecret(key), key)
+	}
+	for _, key := range []string{"index", "sourcetype", "DEST_KEY", "SOURCE_KEY"} {
+		assert.False(t, IsSecret(key), key)
+	}
+}
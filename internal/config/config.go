@@ -4,33 +4,652 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/confmap"
-	"go.yaml.in/yaml/v3"
+	"go.opentelemetry.io/collector/confmap/provider/envprovider"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
 )
 
+// DefaultExporter is the name of the exporter set by the type, endpoint and token keys of tarunner.yaml.
+const DefaultExporter = "default"
+
 type Config struct {
-	Type     string `mapstructure:"type"`
-	Endpoint string `mapstructure:"endpoint"`
-	Token    string `mapstructure:"token"`
+	// Exporter is the exporter used when no named exporters are declared.
+	Exporter `mapstructure:",squash"`
+	// Apps lists the folders of the TAs to run, relative to the base directory.
+	Apps []string `mapstructure:"apps"`
+	// Exporters are the named exporters events may be routed to.
+	Exporters map[string]Exporter `mapstructure:"exporters"`
+	// Routes send the events they match to their exporters. The first matching route applies.
+	Routes []Route `mapstructure:"routes"`
+	// DefaultRoute lists the exporters receiving the events no route matches.
+	DefaultRoute []string `mapstructure:"default_route"`
+	// Storage holds the persistent queues of exporters on disk.
+	Storage *Storage `mapstructure:"storage"`
+	// Metadata sets the default metadata of events, and renames their index.
+	Metadata Metadata `mapstructure:"metadata"`
+	// Inputs override the params of input stanzas, keyed by stanza name or shell pattern. See InputParams.
+	Inputs map[string]map[string]any `mapstructure:"inputs"`
+	// SplunkSecret is the path of the splunk.secret file decrypting the $7$ and $1$ values of tokens, headers and outputs.conf passwords.
+	SplunkSecret string `mapstructure:"splunk_secret"`
+
+	// exporterSet is true if tarunner.yaml sets the type, endpoint or token keys.
+	exporterSet bool
+}
+
+// DefaultStorageMaxSizeMiB is the default disk size of the persistent queues, in MiB.
+const DefaultStorageMaxSizeMiB = 1024
+
+// Storage describes where and how much data the persistent queues of exporters keep on disk.
+type Storage struct {
+	// Directory holds the files of the persistent queues. It is created if it does not exist.
+	Directory string `mapstructure:"directory"`
+	// MaxSizeMiB bounds the size of the persistent queues, shared equally between the exporters using one.
+	// DefaultStorageMaxSizeMiB applies if not set.
+	MaxSizeMiB int64 `mapstructure:"max_size_mib"`
+}
+
+// Metadata sets the host, index and source of events whose input or props do not set them,
+// and renames the indexes TAs send events to.
+type Metadata struct {
+	Host   string `mapstructure:"host"`
+	Index  string `mapstructure:"index"`
+	Source string `mapstructure:"source"`
+	// IndexRename maps the indexes set by inputs to the indexes to send events to.
+	// The empty key renames the index of events without one, and an empty value clears the index of events,
+	// for Index or the default index of the indexer to apply.
+	IndexRename map[string]string `mapstructure:"index_rename"`
 }
 
+// indexName matches valid index names.
+var indexName = regexp.MustCompile(`^[a-z0-9_][a-z0-9_-]*$`)
+
+// Validate checks that the default index and the renamed indexes are valid index names.
+func (m Metadata) Validate() error {
+	var errs []error
+	if m.Index != "" && !indexName.MatchString(m.Index) {
+		errs = append(errs, &FieldError{Path: "index", Err: fmt.Errorf("%q is not a valid index name", m.Index)})
+	}
+	for _, from := range slices.Sorted(maps.Keys(m.IndexRename)) {
+		if to := m.IndexRename[from]; to != "" && !indexName.MatchString(to) {
+			errs = append(errs, &FieldError{Path: "index_rename." + from, Err: fmt.Errorf("%q is not a valid index name", to)})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Exporter describes where and how to send events.
+type Exporter struct {
+	Type     string              `mapstructure:"type"`
+	Endpoint string              `mapstructure:"endpoint"`
+	Token    configopaque.String `mapstructure:"token"`
+	// TLS sets the CA, the client certificate and the server name used to connect to the endpoint.
+	TLS configtls.ClientConfig `mapstructure:"tls"`
+	// ProxyURL is the URL of the proxy to connect through. If not set, the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+	ProxyURL string `mapstructure:"proxy_url"`
+	// Headers are added to each request.
+	Headers configopaque.MapList `mapstructure:"headers"`
+	// Compression is the compression of requests, or of the files of the file exporter: gzip by default, or none.
+	Compression configcompression.Type `mapstructure:"compression"`
+	// Timeout is the timeout of each request. The default timeout of the exporter applies if not set.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// SendingQueue, Batch and RetryOnFailure override the queue, batching and retry settings of the exporter.
+	// Their keys are the keys of the sending_queue, sending_queue::batch and retry_on_failure settings of the collector exporters.
+	SendingQueue   map[string]any `mapstructure:"sending_queue"`
+	Batch          map[string]any `mapstructure:"batch"`
+	RetryOnFailure map[string]any `mapstructure:"retry_on_failure"`
+	// Servers, SendCookedData, UseACK and AutoLBFrequency set how the splunk_s2s exporter forwards events,
+	// like the settings of the same name of outputs.conf. Servers defaults to the endpoint.
+	Servers         []string       `mapstructure:"servers"`
+	SendCookedData  *bool          `mapstructure:"send_cooked_data"`
+	AutoLBFrequency *time.Duration `mapstructure:"auto_lb_frequency"`
+	// UseACK waits for indexers to acknowledge the events sent by the splunk_s2s and splunk_hec exporters.
+	UseACK bool `mapstructure:"use_ack"`
+	// UseTLS connects the splunk_s2s exporter to indexers over TLS, even if no tls setting is set.
+	UseTLS bool `mapstructure:"use_tls"`
+	// Raw sends the raw text of events to the raw endpoint of HEC, as universal forwarders send uncooked data.
+	Raw bool `mapstructure:"raw"`
+	// Protocol, Format, Priority, TimestampFormat, SyslogSourceType and MaxEventSize set how the syslog exporter
+	// sends events to the syslog server at the endpoint, like the syslog settings of outputs.conf.
+	Protocol         string `mapstructure:"protocol"`
+	Format           string `mapstructure:"format"`
+	Priority         string `mapstructure:"priority"`
+	TimestampFormat  string `mapstructure:"timestamp_format"`
+	SyslogSourceType string `mapstructure:"syslog_sourcetype"`
+	MaxEventSize     int    `mapstructure:"max_event_size"`
+	// Directory, MaxFileSizeMiB, MaxFileAge and MaxSpoolSizeMiB set the spool directory of the file exporter,
+	// when its files are rotated, and how large the spool may grow before its oldest files are deleted.
+	// The format of its files is set by Format: hec or otlp_json.
+	Directory       string        `mapstructure:"directory"`
+	MaxFileSizeMiB  int64         `mapstructure:"max_file_size_mib"`
+	MaxFileAge      time.Duration `mapstructure:"max_file_age"`
+	MaxSpoolSizeMiB int64         `mapstructure:"max_spool_size_mib"`
+}
+
+// ExporterTypes lists the supported types of exporters.
+var ExporterTypes = []string{"otlp_http", "otlp_grpc", "splunk_hec", "splunk_s2s", "syslog", "file"}
+
+// placeholderStorageID stands for the storage of persistent queues when validating exporters on their own.
+var placeholderStorageID = component.MustNewID("storage")
+
+// Validate checks the settings of the exporter, loading its certificates.
+// Problems are reported as FieldErrors relative to the exporter.
+func (e Exporter) Validate() error {
+	var errs []error
+	addError := func(path string, err error) {
+		errs = append(errs, &FieldError{Path: path, Err: err})
+	}
+	if !slices.Contains(ExporterTypes, e.Type) {
+		addError("type", fmt.Errorf("unknown exporter type %q, use %s", e.Type, strings.Join(ExporterTypes, ", ")))
+	}
+	switch e.Type {
+	case "otlp_http", "splunk_hec":
+		if err := checkURL(e.Endpoint); err != nil {
+			addError("endpoint", err)
+		}
+	case "otlp_grpc":
+		// gRPC endpoints are host:port, or URLs setting whether to use TLS.
+		if strings.Contains(e.Endpoint, "://") {
+			if err := checkURL(e.Endpoint); err != nil {
+				addError("endpoint", err)
+			}
+		} else if err := checkHostPort(e.Endpoint); err != nil {
+			addError("endpoint", err)
+		}
+	case "splunk_s2s":
+		if len(e.Servers) == 0 {
+			if err := checkHostPort(e.Endpoint); err != nil {
+				addError("endpoint", err)
+			}
+		}
+		for i, server := range e.Servers {
+			if err := checkHostPort(server); err != nil {
+				addError(fmt.Sprintf("servers[%d]", i), err)
+			}
+		}
+	case "syslog":
+		if err := checkHostPort(e.Endpoint); err != nil {
+			addError("endpoint", err)
+		}
+		if e.Protocol != "" && e.Protocol != "udp" && e.Protocol != "tcp" && e.Protocol != "tls" {
+			addError("protocol", fmt.Errorf("%q is not supported, use udp, tcp or tls", e.Protocol))
+		}
+		if e.Format != "" && e.Format != "rfc3164" && e.Format != "rfc5424" {
+			addError("format", fmt.Errorf("%q is not supported, use rfc3164 or rfc5424", e.Format))
+		}
+		if e.MaxEventSize < 0 {
+			addError("max_event_size", errors.New("must not be negative"))
+		}
+	case "file":
+		if e.Endpoint != "" {
+			addError("endpoint", errors.New("not supported by file, set directory instead"))
+		}
+		if e.Directory == "" {
+			addError("directory", errors.New("required by file"))
+		}
+		if e.Format != "" && e.Format != "hec" && e.Format != "otlp_json" {
+			addError("format", fmt.Errorf("%q is not supported by file, use hec or otlp_json", e.Format))
+		}
+		if e.MaxFileSizeMiB < 0 {
+			addError("max_file_size_mib", errors.New("must not be negative"))
+		}
+		if e.MaxFileAge < 0 {
+			addError("max_file_age", errors.New("must not be negative"))
+		}
+		if e.MaxSpoolSizeMiB < 0 {
+			addError("max_spool_size_mib", errors.New("must not be negative"))
+		}
+	}
+	if e.Type != "file" {
+		for _, key := range []struct {
+			name string
+			set  bool
+		}{
+			{"directory", e.Directory != ""},
+			{"max_file_size_mib", e.MaxFileSizeMiB != 0},
+			{"max_file_age", e.MaxFileAge != 0},
+			{"max_spool_size_mib", e.MaxSpoolSizeMiB != 0},
+		} {
+			if key.set {
+				addError(key.name, fmt.Errorf("not supported by %s", e.Type))
+			}
+		}
+	}
+	if e.Type == "splunk_hec" && e.Token == "" {
+		addError("token", errors.New("required by splunk_hec"))
+	}
+	if e.Raw && e.Type != "splunk_hec" {
+		addError("raw", fmt.Errorf("not supported by %s", e.Type))
+	}
+	if e.UseACK && e.Type != "splunk_hec" && e.Type != "splunk_s2s" {
+		addError("use_ack", fmt.Errorf("not supported by %s", e.Type))
+	}
+	if e.UseTLS && e.Type != "splunk_s2s" {
+		addError("use_tls", fmt.Errorf("not supported by %s", e.Type))
+	}
+	if _, err := e.TLS.LoadTLSConfig(context.Background()); err != nil {
+		addError("tls", err)
+	}
+	if e.ProxyURL != "" && e.Type == "otlp_grpc" {
+		addError("proxy_url", errors.New("not supported by otlp_grpc, set the HTTPS_PROXY environment variable instead"))
+	} else if e.ProxyURL != "" && (e.Type == "splunk_s2s" || e.Type == "syslog" || e.Type == "file") {
+		addError("proxy_url", fmt.Errorf("not supported by %s", e.Type))
+	} else if e.ProxyURL != "" {
+		if u, err := url.Parse(e.ProxyURL); err != nil {
+			addError("proxy_url", err)
+		} else if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+			addError("proxy_url", fmt.Errorf("unsupported scheme %q", u.Scheme))
+		}
+	}
+	if err := e.Headers.Validate(); err != nil {
+		addError("headers", err)
+	}
+	switch e.Type {
+	case "otlp_grpc":
+		if e.Compression.IsCompressed() && e.Compression != configcompression.TypeGzip && e.Compression != configcompression.TypeSnappy && e.Compression != configcompression.TypeZstd {
+			addError("compression", fmt.Errorf("%q is not supported by OTLP gRPC, use gzip, snappy, zstd or none", e.Compression))
+		}
+	case "splunk_s2s", "syslog", "file":
+		if e.Compression.IsCompressed() && (e.Type != "file" || e.Compression != configcompression.TypeGzip) {
+			addError("compression", fmt.Errorf("%q is not supported by %s", e.Compression, e.Type))
+		}
+		if len(e.Headers) > 0 {
+			addError("headers", fmt.Errorf("not supported by %s", e.Type))
+		}
+		if e.Token != "" {
+			addError("token", fmt.Errorf("not supported by %s", e.Type))
+		}
+	case "splunk_hec":
+		if e.Compression != "" && e.Compression != configcompression.TypeGzip && e.Compression != "none" {
+			addError("compression", fmt.Errorf("%q is not supported by HEC, use gzip or none", e.Compression))
+		}
+	}
+	if e.Timeout < 0 {
+		addError("timeout", errors.New("must not be negative"))
+	}
+	if e.AutoLBFrequency != nil && *e.AutoLBFrequency < 0 {
+		addError("auto_lb_frequency", errors.New("must not be negative"))
+	}
+	queue := configoptional.Some(exporterhelper.NewDefaultQueueConfig())
+	retry := configretry.NewDefaultBackOffConfig()
+	if err := e.ApplyQueueAndRetry(&queue, &retry, &placeholderStorageID, 1); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// checkURL checks that endpoint is an http or https URL.
+func checkURL(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	switch {
+	case endpoint == "":
+		return errors.New("required")
+	case err != nil:
+		return err
+	case u.Scheme != "http" && u.Scheme != "https":
+		return fmt.Errorf("%q must be an http or https URL", endpoint)
+	case u.Host == "":
+		return fmt.Errorf("%q has no host", endpoint)
+	}
+	return nil
+}
+
+// checkHostPort checks that address is a host:port address.
+func checkHostPort(address string) error {
+	if address == "" {
+		return errors.New("required")
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%q must be a host:port address", address)
+	}
+	if host == "" {
+		return fmt.Errorf("%q has no host", address)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		return fmt.Errorf("%q has an invalid port", address)
+	}
+	return nil
+}
+
+// Addresses returns the host:port addresses the exporter connects to.
+// The port of URLs without one is the default port of their scheme.
+// A syslog exporter sending over UDP and a file exporter have none, as they do not connect.
+func (e Exporter) Addresses() []string {
+	if e.Type == "splunk_s2s" && len(e.Servers) > 0 {
+		return e.Servers
+	}
+	if (e.Type == "syslog" && (e.Protocol == "" || e.Protocol == "udp")) || e.Type == "file" {
+		return nil
+	}
+	u, err := url.Parse(e.Endpoint)
+	if err != nil || u.Host == "" {
+		return []string{e.Endpoint}
+	}
+	if u.Port() != "" {
+		return []string{u.Host}
+	}
+	port := "80"
+	if u.Scheme == "https" {
+		port = "443"
+	}
+	return []string{net.JoinHostPort(u.Hostname(), port)}
+}
+
+// Persistent returns true if the sending queue of the exporter is kept on disk, as set by its persistent key.
+func (e Exporter) Persistent() bool {
+	persistent, _ := e.SendingQueue["persistent"].(bool)
+	return persistent
+}
+
+// Route matches events by index, sourcetype or input stanza name, using shell patterns.
+// An event matches a route if it matches one of the patterns of each key set.
+type Route struct {
+	Index      []string `mapstructure:"index"`
+	SourceType []string `mapstructure:"sourcetype"`
+	Input      []string `mapstructure:"input"`
+	Exporters  []string `mapstructure:"exporters"`
+}
+
+// LoadConfig reads tarunner.yaml, resolving ${env:VAR} references to environment variables
+// and ${file:path} references to the content of files. Unknown keys and invalid settings are rejected:
+// the error joins a FieldError per problem, see Problems.
 func LoadConfig(path string) (*Config, error) {
-	b, err := os.ReadFile(path)
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	resolver, err := confmap.NewResolver(confmap.ResolverSettings{
+		URIs:              []string{"file:" + path},
+		ProviderFactories: []confmap.ProviderFactory{newFileProviderFactory("file:" + path), newEnvProviderFactory()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resolver.Shutdown(context.Background())
+	}()
+	c, err := resolver.Resolve(context.Background())
 	if err != nil {
 		return nil, err
 	}
+	var errs []error
+	exporterSet := false
+	for _, key := range []string{"type", "endpoint", "token"} {
+		if !c.IsSet(key) {
+			continue
+		}
+		exporterSet = true
+		if c.IsSet("exporters") {
+			errs = append(errs, &FieldError{Path: key, Err: errors.New("cannot be set with exporters, declare it under a named exporter")})
+		}
+	}
+	cfg := newConfig()
+	// Report all problems at once: the settings are validated ignoring unknown keys, unless they cannot be decoded.
+	_, err = knownKeys(c.ToStringMap(), cfg)
+	errs = append(errs, err)
+	if err = c.Unmarshal(cfg, confmap.WithIgnoreUnused()); err != nil {
+		return nil, errors.Join(append(errs, decodeErrors(err))...)
+	}
+	cfg.exporterSet = exporterSet
+	errs = append(errs, cfg.Validate())
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// newConfig returns the configuration applying when tarunner.yaml sets nothing: events are sent over OTLP to a local collector.
+func newConfig() *Config {
+	return &Config{
+		Exporter: Exporter{
+			Type:     "otlp_http",
+			Endpoint: "http://localhost:4318",
+		},
+	}
+}
 
-	var rawConf map[string]any
-	if err := yaml.Unmarshal(b, &rawConf); err != nil {
+// fileProvider resolves file:path URIs to the content of the file without its trailing newlines,
+// which secret files usually end with. The content is a string, not parsed as YAML, except for the
+// configuration file at configURI.
+type fileProvider struct {
+	configURI string
+}
+
+func newFileProviderFactory(configURI string) confmap.ProviderFactory {
+	return confmap.NewProviderFactory(func(confmap.ProviderSettings) confmap.Provider {
+		return fileProvider{configURI: configURI}
+	})
+}
+
+func (p fileProvider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	b, err := os.ReadFile(filepath.Clean(strings.TrimPrefix(uri, "file:")))
+	if err != nil {
 		return nil, err
 	}
-	c := confmap.NewFromStringMap(rawConf)
-	cfg := &Config{
-		Type:     "otlp_http",
-		Endpoint: "http://localhost:4318",
+	if uri == p.configURI {
+		return confmap.NewRetrievedFromYAML(b)
+	}
+	return confmap.NewRetrieved(string(bytes.TrimRight(b, "\r\n")))
+}
+
+func (fileProvider) Scheme() string {
+	return "file"
+}
+
+func (fileProvider) Shutdown(context.Context) error {
+	return nil
+}
+
+// envProvider resolves ${env:VAR} references like the collector, but fails on variables that are not set
+// and have no default value, which the collector resolves to an empty string.
+type envProvider struct {
+	confmap.Provider
+}
+
+func newEnvProviderFactory() confmap.ProviderFactory {
+	return confmap.NewProviderFactory(func(set confmap.ProviderSettings) confmap.Provider {
+		return envProvider{Provider: envprovider.NewFactory().Create(set)}
+	})
+}
+
+func (p envProvider) Retrieve(ctx context.Context, uri string, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	name, _, hasDefault := strings.Cut(strings.TrimPrefix(uri, "env:"), ":-")
+	if _, ok := os.LookupEnv(name); !ok && !hasDefault {
+		return nil, fmt.Errorf("environment variable %q is not set", name)
+	}
+	return p.Provider.Retrieve(ctx, uri, watcher)
+}
+
+// NamedExporters returns the exporters declared by the configuration, by name.
+// If no named exporters are declared, the exporter set by the type, endpoint and token keys is named DefaultExporter.
+func (c *Config) NamedExporters() map[string]Exporter {
+	if len(c.Exporters) > 0 {
+		return c.Exporters
+	}
+	return map[string]Exporter{DefaultExporter: c.Exporter}
+}
+
+// ExporterSet returns true if tarunner.yaml sets the type, endpoint or token of the exporter used when no named exporters are declared.
+func (c *Config) ExporterSet() bool {
+	return c.exporterSet
+}
+
+// DefaultExporters returns the names of the exporters receiving the events no route matches.
+func (c *Config) DefaultExporters() []string {
+	if len(c.DefaultRoute) > 0 {
+		return c.DefaultRoute
+	}
+	exporters := c.NamedExporters()
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks the exporters, and that routes are valid and only reference declared exporters.
+// Problems are reported as FieldErrors.
+func (c *Config) Validate() error {
+	exporters := c.NamedExporters()
+	var errs []error
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// spools are the spool directories of the file exporters, which must not share them.
+	spools := map[string]string{}
+	for _, name := range names {
+		e := exporters[name]
+		err := e.Validate()
+		if e.Persistent() && (c.Storage == nil || c.Storage.Directory == "") {
+			err = errors.Join(err, &FieldError{Path: "sending_queue.persistent", Err: errors.New("a persistent queue requires storage.directory to be set")})
+		}
+		if e.Type == "file" && e.Directory != "" {
+			dir := filepath.Clean(e.Directory)
+			if other, ok := spools[dir]; ok {
+				err = errors.Join(err, &FieldError{Path: "directory", Err: fmt.Errorf("already the spool directory of exporter %q", other)})
+			}
+			spools[dir] = name
+		}
+		if err != nil && len(c.Exporters) > 0 {
+			err = withPath("exporters."+name, err)
+		}
+		errs = append(errs, err)
+	}
+	checkExporters := func(where string, names []string) {
+		if len(names) == 0 {
+			errs = append(errs, &FieldError{Path: where, Err: errors.New("no exporters")})
+		}
+		for _, name := range names {
+			if _, ok := exporters[name]; !ok {
+				errs = append(errs, &FieldError{Path: where, Err: fmt.Errorf("unknown exporter %q", name)})
+			}
+		}
+	}
+	for i, r := range c.Routes {
+		where := fmt.Sprintf("routes[%d]", i)
+		if len(r.Index) == 0 && len(r.SourceType) == 0 && len(r.Input) == 0 {
+			errs = append(errs, &FieldError{Path: where, Err: errors.New("set index, sourcetype or input")})
+		}
+		checkPatterns := func(key string, patterns []string) {
+			for _, pattern := range patterns {
+				if _, err := path.Match(pattern, ""); err != nil {
+					errs = append(errs, &FieldError{Path: where + "." + key, Err: fmt.Errorf("invalid pattern %q: %w", pattern, err)})
+				}
+			}
+		}
+		checkPatterns("index", r.Index)
+		checkPatterns("sourcetype", r.SourceType)
+		checkPatterns("input", r.Input)
+		checkExporters(where+".exporters", r.Exporters)
+	}
+	if len(c.DefaultRoute) > 0 {
+		checkExporters("default_route", c.DefaultRoute)
+	}
+	if c.Storage != nil && c.Storage.MaxSizeMiB < 0 {
+		errs = append(errs, &FieldError{Path: "storage.max_size_mib", Err: errors.New("must not be negative")})
+	}
+	if err := c.Metadata.Validate(); err != nil {
+		errs = append(errs, withPath("metadata", err))
+	}
+	for _, key := range slices.Sorted(maps.Keys(c.Inputs)) {
+		if _, err := path.Match(key, ""); err != nil {
+			errs = append(errs, &FieldError{Path: "inputs." + key, Err: fmt.Errorf("invalid pattern %q: %w", key, err)})
+		}
+		params := c.Inputs[key]
+		for _, name := range slices.Sorted(maps.Keys(params)) {
+			if _, err := paramValue(params[name]); err != nil {
+				errs = append(errs, &FieldError{Path: "inputs." + key + "." + name, Err: err})
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// InputParams returns the params of the inputs section overriding the params of the input stanza named stanza.
+// The settings of the patterns matching the stanza apply in lexical order, then the settings of the stanza name itself:
+// the stanza name wins over patterns, and a pattern wins over the patterns sorting before it, whichever is more specific.
+// Booleans are returned as 1 or 0, as in inputs.conf.
+func (c *Config) InputParams(stanza string) map[string]string {
+	var keys []string
+	for _, key := range slices.Sorted(maps.Keys(c.Inputs)) {
+		if ok, _ := path.Match(key, stanza); ok && key != stanza {
+			keys = append(keys, key)
+		}
+	}
+	if _, ok := c.Inputs[stanza]; ok {
+		keys = append(keys, stanza)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	params := map[string]string{}
+	for _, key := range keys {
+		for name, value := range c.Inputs[key] {
+			params[name], _ = paramValue(value)
+		}
+	}
+	return params
+}
+
+// paramValue returns the inputs.conf value of a param of the inputs section.
+// An empty param, such as index with no value, is an empty string.
+func paramValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("must be a string, a number or a boolean, got %T", value)
+	}
+}
+
+// Match returns true if the route matches an event of the given index and sourcetype, read by the given input.
+func (r Route) Match(index, sourceType, input string) bool {
+	return matchAny(r.Index, index) && matchAny(r.SourceType, sourceType) && matchAny(r.Input, input)
+}
+
+// matchAny returns true if there are no patterns, or if one of them matches value.
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
 	}
-	err = c.Unmarshal(cfg)
-	return cfg, err
+	return false
 }
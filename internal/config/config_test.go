@@ -0,0 +1,403 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "tarunner.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, "token: foo\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Exporter{
+		DefaultExporter: {Type: "otlp_http", Endpoint: "http://localhost:4318", Token: "foo"},
+	}, cfg.NamedExporters())
+	assert.Equal(t, []string{DefaultExporter}, cfg.DefaultExporters())
+
+	cfg, err = LoadConfig(writeConfig(t, `exporters:
+  security:
+    type: splunk_hec
+    endpoint: https://hec:8088
+    token: foo
+  ops:
+    type: otlp_http
+    endpoint: http://otel:4318
+routes:
+  - sourcetype: [linux_secure]
+    exporters: [security]
+  - index: [main]
+    exporters: [security, ops]
+default_route: [ops]
+`))
+	require.NoError(t, err)
+	assert.Len(t, cfg.NamedExporters(), 2)
+	assert.Equal(t, Exporter{Type: "splunk_hec", Endpoint: "https://hec:8088", Token: "foo"}, cfg.NamedExporters()["security"])
+	assert.Equal(t, []Route{
+		{SourceType: []string{"linux_secure"}, Exporters: []string{"security"}},
+		{Index: []string{"main"}, Exporters: []string{"security", "ops"}},
+	}, cfg.Routes)
+	assert.Equal(t, []string{"ops"}, cfg.DefaultExporters())
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	_, err := LoadConfig(writeConfig(t, `endpoint: http://otel:4318
+exporters:
+  ops:
+    type: otlp_http
+    endpoint: http://otel:4318
+`))
+	require.EqualError(t, err, "endpoint: cannot be set with exporters, declare it under a named exporter")
+
+	_, err = LoadConfig(writeConfig(t, `exporters:
+  ops:
+    type: otlp_http
+    endpoint: http://otel:4318
+routes:
+  - exporters: [ops]
+  - index: ["[main"]
+    exporters: [security]
+default_route: [missing]
+`))
+	require.ErrorContains(t, err, "routes[0]: set index, sourcetype or input")
+	require.ErrorContains(t, err, `routes[1].index: invalid pattern "[main"`)
+	require.ErrorContains(t, err, `routes[1].exporters: unknown exporter "security"`)
+	require.ErrorContains(t, err, `default_route: unknown exporter "missing"`)
+
+	_, err = LoadConfig(writeConfig(t, `exporters:
+  ops:
+    type: otlp_http
+    endpoint: http://otel:4318
+    sending_queue:
+      persistent: true
+storage:
+  max_size_mib: -1
+`))
+	require.ErrorContains(t, err, "exporters.ops.sending_queue.persistent: a persistent queue requires storage.directory to be set")
+	require.ErrorContains(t, err, "storage.max_size_mib: must not be negative")
+
+	_, err = LoadConfig(writeConfig(t, `type: otlp_grpc
+endpoint: otel:4317
+proxy_url: http://proxy:3128
+compression: lz4
+`))
+	require.ErrorContains(t, err, "proxy_url: not supported by otlp_grpc")
+	require.ErrorContains(t, err, `compression: "lz4" is not supported by OTLP gRPC`)
+
+	_, err = LoadConfig(writeConfig(t, `type: splunk_s2s
+servers: [idx1:9997]
+token: foo
+headers:
+  X-Tenant: security
+`))
+	require.ErrorContains(t, err, "token: not supported by splunk_s2s")
+	require.ErrorContains(t, err, "headers: not supported by splunk_s2s")
+
+	_, err = LoadConfig(writeConfig(t, `type: syslog
+endpoint: http://siem:514
+protocol: sctp
+format: rfc5425
+compression: gzip
+`))
+	require.ErrorContains(t, err, `endpoint: "http://siem:514" must be a host:port address`)
+	require.ErrorContains(t, err, `protocol: "sctp" is not supported, use udp, tcp or tls`)
+	require.ErrorContains(t, err, `format: "rfc5425" is not supported, use rfc3164 or rfc5424`)
+	require.ErrorContains(t, err, `compression: "gzip" is not supported by syslog`)
+
+	_, err = LoadConfig(writeConfig(t, `type: otlp_http
+raw: true
+use_ack: true
+use_tls: true
+`))
+	require.ErrorContains(t, err, "raw: not supported by otlp_http")
+	require.ErrorContains(t, err, "use_ack: not supported by otlp_http")
+	require.ErrorContains(t, err, "use_tls: not supported by otlp_http")
+
+	_, err = LoadConfig(writeConfig(t, `type: file
+endpoint: https://hec:8088
+format: rfc5424
+compression: zstd
+max_file_age: -1m
+`))
+	require.ErrorContains(t, err, "endpoint: not supported by file, set directory instead")
+	require.ErrorContains(t, err, "directory: required by file")
+	require.ErrorContains(t, err, `format: "rfc5424" is not supported by file, use hec or otlp_json`)
+	require.ErrorContains(t, err, `compression: "zstd" is not supported by file`)
+	require.ErrorContains(t, err, "max_file_age: must not be negative")
+
+	_, err = LoadConfig(writeConfig(t, `exporters:
+  spool:
+    type: file
+    directory: /var/spool/tarunner
+  copy:
+    type: file
+    directory: /var/spool/tarunner/
+    compression: gzip
+  hec:
+    type: splunk_hec
+    endpoint: https://hec:8088
+    token: foo
+    max_spool_size_mib: 10
+`))
+	require.ErrorContains(t, err, `exporters.spool.directory: already the spool directory of exporter "copy"`)
+	require.ErrorContains(t, err, "exporters.hec.max_spool_size_mib: not supported by splunk_hec")
+	require.NotContains(t, err.Error(), "compression")
+}
+
+func TestLoadConfigExporterSettings(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, `type: splunk_hec
+endpoint: https://hec:8088
+token: $7$foo
+tls:
+  insecure_skip_verify: true
+  server_name_override: hec.example.com
+proxy_url: http://proxy:3128
+headers:
+  X-Tenant: security
+compression: none
+timeout: 10s
+splunk_secret: /opt/splunkforwarder/etc/auth/splunk.secret
+`))
+	require.NoError(t, err)
+	// Encrypted values are decrypted when exporters are created.
+	assert.Equal(t, "$7$foo", string(cfg.Token))
+	assert.Equal(t, "/opt/splunkforwarder/etc/auth/splunk.secret", cfg.SplunkSecret)
+	assert.True(t, cfg.TLS.InsecureSkipVerify)
+	assert.Equal(t, "hec.example.com", cfg.TLS.ServerName)
+	assert.Equal(t, "http://proxy:3128", cfg.ProxyURL)
+	value, ok := cfg.Headers.Get("X-Tenant")
+	assert.True(t, ok)
+	assert.Equal(t, "security", string(value))
+	assert.Equal(t, "none", string(cfg.Compression))
+	assert.Equal(t, 10*time.Second, cfg.Timeout)
+
+	cfg, err = LoadConfig(writeConfig(t, `type: splunk_s2s
+servers: [idx1:9997, idx2:9997]
+send_cooked_data: false
+auto_lb_frequency: 1m
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"idx1:9997", "idx2:9997"}, cfg.Servers)
+	require.NotNil(t, cfg.SendCookedData)
+	assert.False(t, *cfg.SendCookedData)
+	require.NotNil(t, cfg.AutoLBFrequency)
+	assert.Equal(t, time.Minute, *cfg.AutoLBFrequency)
+
+	_, err = LoadConfig(writeConfig(t, `exporters:
+  security:
+    type: splunk_hec
+    endpoint: https://hec:8088
+    token: foo
+    tls:
+      ca_file: /missing/ca.pem
+    proxy_url: ftp://proxy
+    compression: zstd
+    timeout: -1s
+`))
+	require.ErrorContains(t, err, "exporters.security.tls: ")
+	require.ErrorContains(t, err, `exporters.security.proxy_url: unsupported scheme "ftp"`)
+	require.ErrorContains(t, err, `exporters.security.compression: "zstd" is not supported by HEC, use gzip or none`)
+	require.ErrorContains(t, err, "exporters.security.timeout: must not be negative")
+
+	_, err = LoadConfig(writeConfig(t, "compression: brotli\n"))
+	require.ErrorContains(t, err, "brotli")
+}
+
+func TestLoadConfigStrict(t *testing.T) {
+	_, err := LoadConfig(writeConfig(t, `app: [foo]
+exporters:
+  security:
+    type: hec
+    endpoint: hec:8088
+    tsl:
+      insecure: true
+    batch:
+      flush_timeout: soon
+  ops:
+    type: splunk_hec
+    endpoint: https://hec:8088
+    token: foo
+    retry_on_failure:
+      enabled: true
+      max_interval: -1s
+  fwd:
+    type: splunk_s2s
+    servers: [idx1:9997, idx2]
+routes:
+  - index: [main]
+    exporters: [security]
+    exporter: [ops]
+`))
+	require.Error(t, err)
+	var messages []string
+	for _, problem := range Problems(err) {
+		messages = append(messages, problem.Error())
+	}
+	assert.ElementsMatch(t, []string{
+		"app: unknown key",
+		"exporters.security.tsl: unknown key",
+		"routes[0].exporter: unknown key",
+		`exporters.fwd.servers[1]: "idx2" must be a host:port address`,
+		"exporters.ops.retry_on_failure: 'max_interval' must be non-negative",
+		`exporters.security.type: unknown exporter type "hec", use otlp_http, otlp_grpc, splunk_hec, splunk_s2s, syslog, file`,
+		"exporters.security.batch.flush_timeout: time: invalid duration",
+	}, messages)
+
+	_, err = LoadConfig(writeConfig(t, "type: splunk_hec\nendpoint: hec:8088\n"))
+	require.EqualError(t, err, "endpoint: \"hec:8088\" must be an http or https URL\ntoken: required by splunk_hec")
+}
+
+func TestKnownKeys(t *testing.T) {
+	known, err := knownKeys(map[string]any{
+		"app":      []any{"foo"},
+		"token":    "foo",
+		"tls":      map[string]any{"insecure": true, "ca": "ca.pem"},
+		"headers":  map[string]any{"X-Foo": "bar"},
+		"routes":   []any{map[string]any{"index": []any{"main"}, "exporter": []any{"ops"}}},
+		"metadata": map[string]any{"host": "forwarder01", "hots": "typo"},
+		"inputs":   map[string]any{"script://./bin/*": map[string]any{"anything": 1}},
+		"exporters": map[string]any{
+			"ops": map[string]any{"type": "syslog", "protocl": "tcp"},
+		},
+	}, newConfig())
+	var messages []string
+	for _, problem := range Problems(err) {
+		messages = append(messages, problem.Error())
+	}
+	assert.Equal(t, []string{
+		"app: unknown key",
+		"exporters.ops.protocl: unknown key",
+		"metadata.hots: unknown key",
+		"routes[0].exporter: unknown key",
+		"tls.ca: unknown key",
+	}, messages)
+	// Squashed fields are known, and fields decoding themselves, such as headers, keep their keys.
+	assert.Equal(t, map[string]any{
+		"token":    "foo",
+		"tls":      map[string]any{"insecure": true},
+		"headers":  map[string]any{"X-Foo": "bar"},
+		"routes":   []any{map[string]any{"index": []any{"main"}}},
+		"metadata": map[string]any{"host": "forwarder01"},
+		"inputs":   map[string]any{"script://./bin/*": map[string]any{"anything": 1}},
+		"exporters": map[string]any{
+			"ops": map[string]any{"type": "syslog"},
+		},
+	}, known)
+}
+
+func TestLoadConfigMetadata(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, `token: foo
+metadata:
+  host: forwarder01
+  source: tarunner
+  index_rename:
+    os: linux_os
+    "": main
+`))
+	require.NoError(t, err)
+	assert.Equal(t, Metadata{
+		Host:        "forwarder01",
+		Source:      "tarunner",
+		IndexRename: map[string]string{"os": "linux_os", "": "main"},
+	}, cfg.Metadata)
+
+	_, err = LoadConfig(writeConfig(t, `token: foo
+metadata:
+  index: Main
+  index_rename:
+    os: "linux os"
+    history: ""
+`))
+	require.ErrorContains(t, err, `metadata.index: "Main" is not a valid index name`)
+	require.ErrorContains(t, err, `metadata.index_rename.os: "linux os" is not a valid index name`)
+	require.NotContains(t, err.Error(), "history")
+}
+
+func TestLoadConfigInputs(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, `token: foo
+inputs:
+  "script://./bin/*.sh":
+    interval: 300
+    index: os
+  script://./bin/cpu.sh:
+    interval: 60
+    disabled: true
+  "monitor:///var/log/*.log":
+    index:
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"interval": "60", "index": "os", "disabled": "1"}, cfg.InputParams("script://./bin/cpu.sh"))
+	assert.Equal(t, map[string]string{"interval": "300", "index": "os"}, cfg.InputParams("script://./bin/df.sh"))
+	assert.Equal(t, map[string]string{"index": ""}, cfg.InputParams("monitor:///var/log/*.log"))
+	assert.Nil(t, cfg.InputParams("script://./bin/nested/df.sh"))
+
+	// Overlapping patterns apply in lexical order, so the last one wins.
+	cfg = &Config{Inputs: map[string]map[string]any{
+		"script://./bin/c*":    {"interval": 60},
+		"script://./bin/*.sh":  {"interval": 300, "index": "os"},
+		"script://./bin/*u.sh": {"index": "cpu"},
+	}}
+	assert.Equal(t, map[string]string{"interval": "60", "index": "cpu"}, cfg.InputParams("script://./bin/cpu.sh"))
+
+	_, err = LoadConfig(writeConfig(t, `token: foo
+inputs:
+  "[script":
+    interval: 60
+  script://./bin/cpu.sh:
+    sourcetype: [cpu]
+`))
+	require.ErrorContains(t, err, `inputs.[script: invalid pattern "[script"`)
+	require.ErrorContains(t, err, "inputs.script://./bin/cpu.sh.sourcetype: must be a string, a number or a boolean, got []interface {}")
+}
+
+func TestLoadConfigExpansion(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "hec_token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("0123\n"), 0o600))
+	t.Setenv("TARUNNER_ENDPOINT", "https://hec:8088")
+	cfg, err := LoadConfig(writeConfig(t, `type: splunk_hec
+endpoint: ${env:TARUNNER_ENDPOINT}/services/collector
+token: ${file:`+tokenFile+`}
+timeout: ${env:TARUNNER_TIMEOUT:-5s}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "https://hec:8088/services/collector", cfg.Endpoint)
+	assert.Equal(t, "0123", string(cfg.Token))
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.NotContains(t, fmt.Sprintf("%+v", cfg), "0123")
+
+	// Tokens are read as is, even if they look like YAML.
+	for _, token := range []string{"a: b", "12345", "[a]", "true"} {
+		require.NoError(t, os.WriteFile(tokenFile, []byte(token+"\n"), 0o600))
+		cfg, err = LoadConfig(writeConfig(t, "type: splunk_hec\nendpoint: https://hec:8088\ntoken: ${file:"+tokenFile+"}\n"))
+		require.NoError(t, err, token)
+		assert.Equal(t, token, string(cfg.Token))
+	}
+
+	_, err = LoadConfig(writeConfig(t, "token: ${env:TARUNNER_MISSING}\n"))
+	require.ErrorContains(t, err, `environment variable "TARUNNER_MISSING" is not set`)
+	_, err = LoadConfig(writeConfig(t, "token: ${file:"+filepath.Join(t.TempDir(), "missing")+"}\n"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestExporterAddresses(t *testing.T) {
+	assert.Equal(t, []string{"hec:8088"}, Exporter{Type: "splunk_hec", Endpoint: "https://hec:8088/services/collector"}.Addresses())
+	assert.Equal(t, []string{"otel:443"}, Exporter{Type: "otlp_http", Endpoint: "https://otel"}.Addresses())
+	assert.Equal(t, []string{"otel:4317"}, Exporter{Type: "otlp_grpc", Endpoint: "otel:4317"}.Addresses())
+	assert.Equal(t, []string{"idx1:9997", "idx2:9997"}, Exporter{Type: "splunk_s2s", Servers: []string{"idx1:9997", "idx2:9997"}}.Addresses())
+	assert.Equal(t, []string{"siem:6514"}, Exporter{Type: "syslog", Endpoint: "siem:6514", Protocol: "tls"}.Addresses())
+	assert.Empty(t, Exporter{Type: "syslog", Endpoint: "siem:514"}.Addresses())
+	assert.Empty(t, Exporter{Type: "file", Directory: "/var/spool/tarunner"}.Addresses())
+}
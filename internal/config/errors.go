@@ -0,0 +1,210 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// FieldError is a problem with a field of tarunner.yaml, identified by its path, such as exporters.security.tls.ca_file.
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Problems returns the problems joined in err, one error per problem.
+func Problems(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var problems []error
+		for _, e := range joined.Unwrap() {
+			problems = append(problems, Problems(e)...)
+		}
+		return problems
+	}
+	return []error{err}
+}
+
+// withPath returns the problems of err with their path prefixed by path.
+func withPath(path string, err error) error {
+	var errs []error
+	for _, problem := range Problems(err) {
+		var fe *FieldError
+		if errors.As(problem, &fe) && fe.Path != "" {
+			errs = append(errs, &FieldError{Path: path + "." + fe.Path, Err: fe.Err})
+		} else {
+			errs = append(errs, &FieldError{Path: path, Err: problem})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mapKey matches the keys of maps in the field names of mapstructure, such as [security] in exporters[security].tls.
+var mapKey = regexp.MustCompile(`\[([^\]0-9][^\]]*)\]`)
+
+// typeName matches the names mapstructure gives to the root of the decoded configuration, such as config.Config.
+var typeName = regexp.MustCompile(`^\w+\.[A-Z]\w*$`)
+
+// knownKeys returns the keys of raw that the fields of v decode, and a problem per unknown key.
+// The keys are compared with the mapstructure tags of the fields, so the unknown keys are found
+// without decoding; fields decoding themselves, such as configoptional.Optional, check their own keys.
+func knownKeys(raw map[string]any, v any) (map[string]any, error) {
+	known, errs := pruneKeys(raw, reflect.TypeOf(v), "", true)
+	out, _ := known.(map[string]any)
+	return out, errors.Join(errs...)
+}
+
+var unmarshalerType = reflect.TypeFor[confmap.Unmarshaler]()
+
+func pruneKeys(raw any, t reflect.Type, path string, root bool) (any, []error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if !root && reflect.PointerTo(t).Implements(unmarshalerType) {
+		return raw, nil
+	}
+	var errs []error
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := raw.(map[string]any)
+		if !ok {
+			return raw, nil
+		}
+		fields, remain := structFields(t)
+		out := make(map[string]any, len(m))
+		for _, key := range slices.Sorted(maps.Keys(m)) {
+			ft, ok := fields[key]
+			switch {
+			case ok:
+				var fieldErrs []error
+				out[key], fieldErrs = pruneKeys(m[key], ft, keyPath(path, key), false)
+				errs = append(errs, fieldErrs...)
+			case remain:
+				out[key] = m[key]
+			default:
+				errs = append(errs, &FieldError{Path: keyPath(path, key), Err: errors.New("unknown key")})
+			}
+		}
+		return out, errs
+	case reflect.Map:
+		m, ok := raw.(map[string]any)
+		if !ok || t.Key().Kind() != reflect.String {
+			return raw, nil
+		}
+		out := make(map[string]any, len(m))
+		for _, key := range slices.Sorted(maps.Keys(m)) {
+			var elemErrs []error
+			out[key], elemErrs = pruneKeys(m[key], t.Elem(), keyPath(path, key), false)
+			errs = append(errs, elemErrs...)
+		}
+		return out, errs
+	case reflect.Slice, reflect.Array:
+		s, ok := raw.([]any)
+		if !ok {
+			return raw, nil
+		}
+		out := make([]any, len(s))
+		for i, elem := range s {
+			var elemErrs []error
+			out[i], elemErrs = pruneKeys(elem, t.Elem(), fmt.Sprintf("%s[%d]", path, i), false)
+			errs = append(errs, elemErrs...)
+		}
+		return out, errs
+	default:
+		return raw, nil
+	}
+}
+
+// structFields returns the types of the fields of t by key, including the fields of squashed structs,
+// and whether t keeps the remaining keys.
+func structFields(t reflect.Type) (map[string]reflect.Type, bool) {
+	fields := map[string]reflect.Type{}
+	remain := false
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		switch {
+		case name == "-":
+		case slices.Contains(strings.Split(opts, ","), "remain"):
+			remain = true
+		case slices.Contains(strings.Split(opts, ","), "squash"):
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			squashed, squashedRemain := structFields(ft)
+			maps.Copy(fields, squashed)
+			remain = remain || squashedRemain
+		case name == "":
+			fields[f.Name] = f.Type
+		default:
+			fields[name] = f.Type
+		}
+	}
+	return fields, remain
+}
+
+func keyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// decodeErrors returns the problems of an error returned when decoding a configuration, one per field.
+func decodeErrors(err error) error {
+	if unwrapped := errors.Unwrap(err); unwrapped != nil {
+		err = unwrapped
+	}
+	var errs []error
+	for _, problem := range Problems(err) {
+		var de *mapstructure.DecodeError
+		if !errors.As(problem, &de) {
+			errs = append(errs, problem)
+			continue
+		}
+		path := mapKey.ReplaceAllString(de.Name(), ".$1")
+		if typeName.MatchString(path) {
+			path = ""
+		}
+		inner := de.Unwrap()
+		switch {
+		case errors.As(inner, new(*mapstructure.DecodeError)):
+			// Fields decoding themselves report the problems of their own fields.
+			if path == "" {
+				errs = append(errs, decodeErrors(inner))
+			} else {
+				errs = append(errs, withPath(path, decodeErrors(inner)))
+			}
+		default:
+			errs = append(errs, &FieldError{Path: path, Err: inner})
+		}
+	}
+	return errors.Join(errs...)
+}
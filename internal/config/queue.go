@@ -0,0 +1,128 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+// ApplyQueueAndRetry applies the sending_queue, batch and retry_on_failure settings of the exporter to the queue and retry settings
+// of a collector exporter. Each section may be disabled with `enabled: false`. A queue set with `persistent: true` is kept in
+// the storage extension storageID, and its size of maxBytes is counted in bytes.
+func (e Exporter) ApplyQueueAndRetry(queue *configoptional.Optional[exporterhelper.QueueBatchConfig], retry *configretry.BackOffConfig, storageID *component.ID, maxBytes int64) error {
+	var errs []error
+	queueConf := maps.Clone(e.SendingQueue)
+	queueEnabled, err := popBool(queueConf, "enabled", true)
+	if err != nil {
+		errs = append(errs, &FieldError{Path: "sending_queue.enabled", Err: err})
+	}
+	persistent, err := popBool(queueConf, "persistent", false)
+	if err != nil {
+		errs = append(errs, &FieldError{Path: "sending_queue.persistent", Err: err})
+	}
+	if persistent {
+		switch {
+		case !queueEnabled:
+			errs = append(errs, &FieldError{Path: "sending_queue.persistent", Err: errors.New("a persistent queue must be enabled")})
+		case storageID == nil:
+			errs = append(errs, &FieldError{Path: "sending_queue.persistent", Err: errors.New("a persistent queue requires storage.directory to be set")})
+		case queueConf["queue_size"] != nil || queueConf["sizer"] != nil:
+			errs = append(errs, &FieldError{Path: "sending_queue", Err: errors.New("the size of a persistent queue is set by storage.max_size_mib")})
+		}
+	}
+	batchConf := maps.Clone(e.Batch)
+	batchEnabled, err := popBool(batchConf, "enabled", true)
+	if err != nil {
+		errs = append(errs, &FieldError{Path: "batch.enabled", Err: err})
+	}
+	if e.Batch != nil && batchEnabled && !queueEnabled {
+		errs = append(errs, &FieldError{Path: "batch", Err: errors.New("batching requires the sending queue")})
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	q, r := *queue, *retry
+	if e.SendingQueue != nil || e.Batch != nil {
+		qc := q.GetOrInsertDefault()
+		errs = append(errs, decodeSection("sending_queue", queueConf, qc))
+		if e.Batch != nil {
+			// exporterhelper batches the requests of its queue. Batches are sized like the queue, unless set.
+			if sizer, ok := queueConf["sizer"]; ok && batchConf["sizer"] == nil {
+				batchConf["sizer"] = sizer
+			}
+			errs = append(errs, decodeSection("batch", batchConf, qc.Batch.GetOrInsertDefault()))
+		}
+	}
+	if e.RetryOnFailure != nil {
+		errs = append(errs, decodeSection("retry_on_failure", e.RetryOnFailure, &r))
+	}
+	if err = errors.Join(errs...); err != nil {
+		return err
+	}
+	switch {
+	case !queueEnabled:
+		q = configoptional.None[exporterhelper.QueueBatchConfig]()
+	case !batchEnabled:
+		q.GetOrInsertDefault().Batch = configoptional.None[exporterhelper.BatchConfig]()
+	}
+	if persistent {
+		qc := q.GetOrInsertDefault()
+		qc.StorageID = storageID
+		qc.Sizer = exporterhelper.RequestSizerTypeBytes
+		qc.QueueSize = maxBytes
+	}
+	if q.HasValue() {
+		if err = q.Get().Validate(); err != nil {
+			errs = append(errs, &FieldError{Path: "sending_queue", Err: err})
+		}
+		if q.Get().Batch.HasValue() {
+			if err = q.Get().Batch.Get().Validate(); err != nil {
+				errs = append(errs, &FieldError{Path: "batch", Err: err})
+			}
+		}
+	}
+	if err = r.Validate(); err != nil {
+		errs = append(errs, &FieldError{Path: "retry_on_failure", Err: err})
+	}
+	if err = errors.Join(errs...); err != nil {
+		return err
+	}
+	*queue, *retry = q, r
+	return nil
+}
+
+// decodeSection decodes the known keys of the section of tarunner.yaml at path into v.
+func decodeSection(path string, section map[string]any, v any) error {
+	known, err := knownKeys(section, v)
+	if decodeErr := confmap.NewFromStringMap(known).Unmarshal(v); decodeErr != nil {
+		err = errors.Join(err, decodeErrors(decodeErr))
+	}
+	if err != nil {
+		return withPath(path, err)
+	}
+	return nil
+}
+
+// popBool removes a boolean key of a section, and returns its value, or def if the key is not set.
+func popBool(section map[string]any, key string, def bool) (bool, error) {
+	v, ok := section[key]
+	if !ok {
+		return def, nil
+	}
+	delete(section, key)
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("must be true or false, found %v", v)
+	}
+	return b, nil
+}
@@ -0,0 +1,74 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+func TestApplyQueueAndRetry(t *testing.T) {
+	queue := configoptional.Some(exporterhelper.NewDefaultQueueConfig())
+	retry := configretry.NewDefaultBackOffConfig()
+	require.NoError(t, Exporter{
+		SendingQueue:   map[string]any{"queue_size": 5000, "num_consumers": 2, "block_on_overflow": true},
+		Batch:          map[string]any{"flush_timeout": "1s", "min_size": 100},
+		RetryOnFailure: map[string]any{"initial_interval": "1s", "max_elapsed_time": 0},
+	}.ApplyQueueAndRetry(&queue, &retry, nil, 0))
+	require.True(t, queue.HasValue())
+	assert.Equal(t, int64(5000), queue.Get().QueueSize)
+	assert.Equal(t, 2, queue.Get().NumConsumers)
+	assert.True(t, queue.Get().BlockOnOverflow)
+	require.True(t, queue.Get().Batch.HasValue())
+	assert.Equal(t, time.Second, queue.Get().Batch.Get().FlushTimeout)
+	assert.Equal(t, int64(100), queue.Get().Batch.Get().MinSize)
+	assert.True(t, retry.Enabled)
+	assert.Equal(t, time.Second, retry.InitialInterval)
+	assert.Equal(t, time.Duration(0), retry.MaxElapsedTime)
+	assert.Equal(t, configretry.NewDefaultBackOffConfig().MaxInterval, retry.MaxInterval)
+
+	require.NoError(t, Exporter{
+		SendingQueue:   map[string]any{"enabled": false},
+		RetryOnFailure: map[string]any{"enabled": false},
+	}.ApplyQueueAndRetry(&queue, &retry, nil, 0))
+	assert.False(t, queue.HasValue())
+	assert.False(t, retry.Enabled)
+
+	queue = configoptional.Some(exporterhelper.NewDefaultQueueConfig())
+	err := Exporter{
+		SendingQueue: map[string]any{"enabled": false},
+		Batch:        map[string]any{"min_size": 10},
+	}.ApplyQueueAndRetry(&queue, &retry, nil, 0)
+	require.EqualError(t, err, "batch: batching requires the sending queue")
+	err = Exporter{SendingQueue: map[string]any{"queue_size": -1}}.ApplyQueueAndRetry(&queue, &retry, nil, 0)
+	require.EqualError(t, err, "sending_queue: `queue_size` must be positive")
+	err = Exporter{RetryOnFailure: map[string]any{"max_elapsed": "1m"}}.ApplyQueueAndRetry(&queue, &retry, nil, 0)
+	require.EqualError(t, err, "retry_on_failure.max_elapsed: unknown key")
+
+	storageID := component.MustNewID("file_storage")
+	require.NoError(t, Exporter{SendingQueue: map[string]any{"persistent": true}}.ApplyQueueAndRetry(&queue, &retry, &storageID, 1<<20))
+	assert.Equal(t, &storageID, queue.Get().StorageID)
+	assert.Equal(t, exporterhelper.RequestSizerTypeBytes, queue.Get().Sizer)
+	assert.Equal(t, int64(1<<20), queue.Get().QueueSize)
+	err = Exporter{SendingQueue: map[string]any{"persistent": true, "queue_size": 10}}.ApplyQueueAndRetry(&queue, &retry, &storageID, 1<<20)
+	require.EqualError(t, err, "sending_queue: the size of a persistent queue is set by storage.max_size_mib")
+
+	err = Exporter{
+		SendingQueue:   map[string]any{"num_consumers": "many"},
+		Batch:          map[string]any{"flush_timeout": "1s", "max_batch": 10},
+		RetryOnFailure: map[string]any{"max_elapsed": "1m", "max_interval": "1m"},
+	}.ApplyQueueAndRetry(&queue, &retry, nil, 0)
+	require.Error(t, err)
+	assert.Len(t, Problems(err), 3)
+	assert.ErrorContains(t, err, "sending_queue.num_consumers: ")
+	assert.ErrorContains(t, err, "batch.max_batch: unknown key")
+	assert.ErrorContains(t, err, "retry_on_failure.max_elapsed: unknown key")
+}
@@ -0,0 +1,60 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package fileexporter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+// Formats of the lines of spool files.
+const (
+	// FormatHEC writes a HEC event per line, as the event endpoint receives them.
+	FormatHEC = "hec"
+	// FormatOTLPJSON writes an OTLP/JSON export request per line, holding a batch of log records.
+	FormatOTLPJSON = "otlp_json"
+)
+
+type Config struct {
+	QueueConfig configoptional.Optional[exporterhelper.QueueBatchConfig] `mapstructure:"sending_queue"`
+	RetryConfig configretry.BackOffConfig                                `mapstructure:"retry_on_failure"`
+
+	// Directory is the spool directory. It is created if it does not exist.
+	Directory string `mapstructure:"directory"`
+	// Format is hec or otlp_json.
+	Format string `mapstructure:"format"`
+	// Compress compresses files with gzip.
+	Compress bool `mapstructure:"compress"`
+	// MaxFileSizeMiB is the size of files, compressed, from which they are rotated.
+	MaxFileSizeMiB int64 `mapstructure:"max_file_size_mib"`
+	// MaxFileAge is the age of files from which they are rotated.
+	MaxFileAge time.Duration `mapstructure:"max_file_age"`
+	// MaxSpoolSizeMiB bounds the size of the rotated files of the spool directory. The oldest files are deleted past it.
+	MaxSpoolSizeMiB int64 `mapstructure:"max_spool_size_mib"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.Directory == "" {
+		errs = append(errs, errors.New("requires a directory"))
+	}
+	if cfg.Format != FormatHEC && cfg.Format != FormatOTLPJSON {
+		errs = append(errs, fmt.Errorf("format %q is not supported, use hec or otlp_json", cfg.Format))
+	}
+	if cfg.MaxFileSizeMiB <= 0 {
+		errs = append(errs, errors.New("max_file_size_mib must be positive"))
+	}
+	if cfg.MaxFileAge <= 0 {
+		errs = append(errs, errors.New("max_file_age must be positive"))
+	}
+	if cfg.MaxSpoolSizeMiB < cfg.MaxFileSizeMiB {
+		errs = append(errs, errors.New("max_spool_size_mib must not be less than max_file_size_mib"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,7 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fileexporter writes log records to files of a spool directory, one JSON object per line,
+// to carry them to where they can be sent, as with tarunner ship. Files are rotated by size and age,
+// and the oldest files are deleted to keep the spool under its maximum size.
+package fileexporter
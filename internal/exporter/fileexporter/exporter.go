@@ -0,0 +1,204 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package fileexporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/splunk/tarunner/internal/exporter/hecexporter"
+)
+
+// fileExporter writes log records to the current file of the spool directory, and rotates it by size and age.
+type fileExporter struct {
+	cfg    *Config
+	logger *zap.Logger
+
+	mu   sync.Mutex
+	file *spoolFile
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// spoolFile is the file being written.
+type spoolFile struct {
+	f       *os.File
+	w       io.Writer
+	gz      *gzip.Writer
+	size    int64
+	created time.Time
+}
+
+func (f *spoolFile) Write(p []byte) (int, error) {
+	n, err := f.f.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func newFileExporter(cfg *Config, logger *zap.Logger) *fileExporter {
+	return &fileExporter{cfg: cfg, logger: logger}
+}
+
+func (e *fileExporter) start(context.Context, component.Host) error {
+	if err := os.MkdirAll(e.cfg.Directory, 0o700); err != nil {
+		return err
+	}
+	// Files left by a previous run are complete, but for their last line.
+	entries, err := os.ReadDir(e.cfg.Directory)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), partSuffix); ok && strings.HasPrefix(name, filePrefix) {
+			if err = os.Rename(filepath.Join(e.cfg.Directory, entry.Name()), filepath.Join(e.cfg.Directory, name)); err != nil {
+				return err
+			}
+		}
+	}
+	e.enforceMaxSpoolSize()
+
+	e.done = make(chan struct{})
+	e.wg.Add(1)
+	go e.rotateByAge()
+	return nil
+}
+
+func (e *fileExporter) shutdown(context.Context) error {
+	if e.done != nil {
+		close(e.done)
+		e.wg.Wait()
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.rotate()
+}
+
+// rotateByAge rotates the current file once it is older than the maximum age of files.
+func (e *fileExporter) rotateByAge() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(min(e.cfg.MaxFileAge, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+		}
+		e.mu.Lock()
+		if e.file != nil && time.Since(e.file.created) >= e.cfg.MaxFileAge {
+			if err := e.rotate(); err != nil {
+				e.logger.Error("Failed to rotate spool file", zap.Error(err))
+			}
+		}
+		e.mu.Unlock()
+	}
+}
+
+func (e *fileExporter) pushLogs(_ context.Context, ld plog.Logs) error {
+	var b bytes.Buffer
+	if e.cfg.Format == FormatOTLPJSON {
+		data, err := (&plog.JSONMarshaler{}).MarshalLogs(ld)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	} else if err := hecexporter.WriteEvents(&b, ld); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil {
+		if err := e.create(); err != nil {
+			return err
+		}
+	}
+	if _, err := e.file.w.Write(b.Bytes()); err != nil {
+		return err
+	}
+	// Lines are flushed with each batch, to be read back after a crash.
+	if e.file.gz != nil {
+		if err := e.file.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	if e.file.size >= e.cfg.MaxFileSizeMiB<<20 {
+		return e.rotate()
+	}
+	return nil
+}
+
+// create creates the current file.
+func (e *fileExporter) create() error {
+	now := time.Now()
+	path := filepath.Join(e.cfg.Directory, fileName(now, e.cfg.Format, e.cfg.Compress)+partSuffix)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	e.file = &spoolFile{f: f, created: now}
+	e.file.w = e.file
+	if e.cfg.Compress {
+		e.file.gz = gzip.NewWriter(e.file)
+		e.file.w = e.file.gz
+	}
+	return nil
+}
+
+// rotate closes the current file, if any, making it available to ship, and deletes the oldest files
+// past the maximum size of the spool.
+func (e *fileExporter) rotate() error {
+	if e.file == nil {
+		return nil
+	}
+	file := e.file
+	e.file = nil
+	var errs []error
+	if file.gz != nil {
+		errs = append(errs, file.gz.Close())
+	}
+	errs = append(errs, file.f.Close())
+	path := file.f.Name()
+	errs = append(errs, os.Rename(path, strings.TrimSuffix(path, partSuffix)))
+	e.enforceMaxSpoolSize()
+	return errors.Join(errs...)
+}
+
+// enforceMaxSpoolSize deletes the oldest files of the spool until their total size is under its maximum size.
+func (e *fileExporter) enforceMaxSpoolSize() {
+	files, err := Files(e.cfg.Directory)
+	if err != nil {
+		e.logger.Error("Failed to list spool files", zap.Error(err))
+		return
+	}
+	sizes := make([]int64, len(files))
+	var total int64
+	for i, path := range files {
+		if info, err := os.Stat(path); err == nil {
+			sizes[i] = info.Size()
+			total += sizes[i]
+		}
+	}
+	for i := 0; i < len(files) && total > e.cfg.MaxSpoolSizeMiB<<20; i++ {
+		if err = os.Remove(files[i]); err != nil {
+			e.logger.Error("Failed to delete spool file", zap.String("file", files[i]), zap.Error(err))
+			continue
+		}
+		total -= sizes[i]
+		e.logger.Warn("Deleted the oldest spool file to stay under max_spool_size_mib: its events are lost", zap.String("file", files[i]))
+	}
+}
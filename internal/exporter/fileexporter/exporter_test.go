@@ -0,0 +1,154 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package fileexporter
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// newExporter creates an exporter writing synchronously to dir, without queue nor retries.
+func newExporter(t *testing.T, dir string, configure func(*Config)) exporter.Logs {
+	f := NewFactory()
+	cfg := f.CreateDefaultConfig().(*Config)
+	cfg.Directory = dir
+	cfg.QueueConfig = configoptional.None[exporterhelper.QueueBatchConfig]()
+	cfg.RetryConfig.Enabled = false
+	if configure != nil {
+		configure(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+	e, err := f.CreateLogs(context.Background(), exporter.Settings{
+		ID:                component.NewID(componentType),
+		TelemetrySettings: componenttest.NewNopTelemetrySettings(),
+	}, cfg)
+	require.NoError(t, err)
+	return e
+}
+
+func newLogs(bodies ...string) plog.Logs {
+	ld := plog.NewLogs()
+	sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	for _, body := range bodies {
+		lr := sl.LogRecords().AppendEmpty()
+		lr.Body().SetStr(body)
+		lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+		lr.Attributes().PutStr("com.splunk.sourcetype", "app")
+	}
+	return ld
+}
+
+// readBodies returns the bodies of the log records of the spool files of dir.
+func readBodies(t *testing.T, dir string) []string {
+	files, err := Files(dir)
+	require.NoError(t, err)
+	var bodies []string
+	for _, path := range files {
+		require.NoError(t, ReadFile(path, func(ld plog.Logs) error {
+			for _, rl := range ld.ResourceLogs().All() {
+				for _, sl := range rl.ScopeLogs().All() {
+					for _, lr := range sl.LogRecords().All() {
+						bodies = append(bodies, lr.Body().AsString())
+					}
+				}
+			}
+			return nil
+		}))
+	}
+	return bodies
+}
+
+func TestWriteFiles(t *testing.T) {
+	for _, format := range []string{FormatHEC, FormatOTLPJSON} {
+		for _, compress := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s compress=%v", format, compress), func(t *testing.T) {
+				dir := t.TempDir()
+				e := newExporter(t, dir, func(cfg *Config) {
+					cfg.Format = format
+					cfg.Compress = compress
+				})
+				require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+				require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("a", "b")))
+				require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("c")))
+				// The current file is not shipped until rotated.
+				files, err := Files(dir)
+				require.NoError(t, err)
+				assert.Empty(t, files)
+				require.NoError(t, e.Shutdown(context.Background()))
+
+				files, err = Files(dir)
+				require.NoError(t, err)
+				require.Len(t, files, 1)
+				assert.Equal(t, compress, strings.HasSuffix(files[0], ".gz"))
+				assert.Equal(t, []string{"a", "b", "c"}, readBodies(t, dir))
+			})
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	e := newExporter(t, dir, func(cfg *Config) {
+		cfg.Compress = false
+		cfg.MaxFileSizeMiB = 1
+		cfg.MaxFileAge = 50 * time.Millisecond
+		cfg.MaxSpoolSizeMiB = 2
+	})
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, e.Shutdown(context.Background()))
+	}()
+
+	require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("a")))
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		files, err := Files(dir)
+		assert.NoError(tt, err)
+		assert.Len(tt, files, 1)
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Files past the maximum size are rotated, and the oldest files deleted past the maximum size of the spool.
+	large := strings.Repeat("x", 1<<20)
+	for _, body := range []string{"1", "2"} {
+		require.NoError(t, e.ConsumeLogs(context.Background(), newLogs(body+large)))
+	}
+	bodies := readBodies(t, dir)
+	require.Len(t, bodies, 1)
+	assert.Equal(t, "2"+large, bodies[0])
+}
+
+func TestReadIncompleteFile(t *testing.T) {
+	dir := t.TempDir()
+	// A file left by a crash has no gzip trailer, and may end with an incomplete line.
+	path := filepath.Join(dir, fileName(time.Now(), FormatHEC, true)+partSuffix)
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	_, err = gz.Write([]byte(`{"event":"a"}` + "\n" + `{"event":"b`))
+	require.NoError(t, err)
+	require.NoError(t, gz.Flush())
+	require.NoError(t, f.Close())
+
+	e := newExporter(t, dir, nil)
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, e.Shutdown(context.Background()))
+	assert.Equal(t, []string{"a"}, readBodies(t, dir))
+
+	require.EqualError(t, ReadFile(filepath.Join(dir, "foo.log"), nil), filepath.Join(dir, "foo.log")+" is not a spool file")
+}
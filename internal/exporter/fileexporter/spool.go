@@ -0,0 +1,126 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package fileexporter
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/splunk/tarunner/internal/exporter/hecexporter"
+)
+
+// Spool files are named spool-<UTC time of creation>.<format>.ndjson, with a .gz suffix if compressed.
+// Names sort in the order files were created. The file being written has a .part suffix.
+const (
+	filePrefix  = "spool-"
+	fileTime    = "20060102T150405.000000000Z"
+	partSuffix  = ".part"
+	gzipSuffix  = ".gz"
+	ndjsonExt   = ".ndjson"
+	hecExt      = ".hec" + ndjsonExt
+	otlpJSONExt = ".otlp" + ndjsonExt
+)
+
+// maxBatchSize is the number of HEC events ReadFile passes at once.
+const maxBatchSize = 1000
+
+// fileName returns the name of a spool file created at t.
+func fileName(t time.Time, format string, compress bool) string {
+	name := filePrefix + t.UTC().Format(fileTime) + hecExt
+	if format == FormatOTLPJSON {
+		name = filePrefix + t.UTC().Format(fileTime) + otlpJSONExt
+	}
+	if compress {
+		name += gzipSuffix
+	}
+	return name
+}
+
+// Files returns the paths of the spool files of dir that are complete, oldest first.
+func Files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(strings.TrimSuffix(name, gzipSuffix), ndjsonExt) {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	return files, nil
+}
+
+// ReadFile reads a spool file, passing its log records to consume in batches, in order.
+// The last line of a file that was not closed, if incomplete, is ignored.
+func ReadFile(path string, consume func(plog.Logs) error) error {
+	name := strings.TrimSuffix(filepath.Base(path), gzipSuffix)
+	if !strings.HasSuffix(name, hecExt) && !strings.HasSuffix(name, otlpJSONExt) {
+		return fmt.Errorf("%s is not a spool file", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, gzipSuffix) {
+		gz, err := gzip.NewReader(f)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	br := bufio.NewReader(r)
+	batch := plog.NewLogs()
+	records := batch.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(name, otlpJSONExt) {
+			var ld plog.Logs
+			if ld, err = (&plog.JSONUnmarshaler{}).UnmarshalLogs(line); err != nil {
+				return fmt.Errorf("line %d: %w", n, err)
+			}
+			if err = consume(ld); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = hecexporter.UnmarshalEvent(line, records.AppendEmpty()); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+		if records.Len() == maxBatchSize {
+			if err = consume(batch); err != nil {
+				return err
+			}
+			batch = plog.NewLogs()
+			records = batch.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+		}
+	}
+	if records.Len() > 0 {
+		return consume(batch)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package hecexporter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+type Config struct {
+	TimeoutConfig exporterhelper.TimeoutConfig                             `mapstructure:",squash"`
+	QueueConfig   configoptional.Optional[exporterhelper.QueueBatchConfig] `mapstructure:"sending_queue"`
+	RetryConfig   configretry.BackOffConfig                                `mapstructure:"retry_on_failure"`
+	// ClientConfig sets the endpoint of the HTTP Event Collector, such as https://hec:8088/services/collector.
+	ClientConfig confighttp.ClientConfig `mapstructure:",squash"`
+
+	Token configopaque.String `mapstructure:"token"`
+	// Raw sends the raw text of log records to the raw endpoint, one request per index, host, source and sourcetype.
+	// Indexers then parse events as they parse the data of universal forwarders.
+	Raw bool `mapstructure:"raw"`
+	// UseACK waits for indexers to acknowledge each batch of log records before the timeout, and fails the batch otherwise.
+	UseACK bool `mapstructure:"use_ack"`
+	// AckPollInterval is how often to query the acknowledgement of a batch.
+	AckPollInterval time.Duration `mapstructure:"ack_poll_interval"`
+	// DisableCompression sends requests without gzip compression.
+	DisableCompression bool `mapstructure:"disable_compression"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs []error
+	if u, err := url.Parse(cfg.ClientConfig.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("endpoint %q is not an http or https URL", cfg.ClientConfig.Endpoint))
+	}
+	if cfg.Token == "" {
+		errs = append(errs, errors.New("requires a token"))
+	}
+	if cfg.UseACK && cfg.AckPollInterval <= 0 {
+		errs = append(errs, errors.New("ack_poll_interval must be positive"))
+	}
+	return errors.Join(errs...)
+}
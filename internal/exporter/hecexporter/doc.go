@@ -0,0 +1,7 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package hecexporter sends log records to the raw or event endpoint of Splunk HEC, on a channel,
+// optionally waiting for indexers to acknowledge them. It is used where the contrib exporter,
+// which supports neither the raw endpoint nor indexer acknowledgement, does not apply.
+package hecexporter
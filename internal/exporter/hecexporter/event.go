@@ -0,0 +1,112 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package hecexporter
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Attributes holding the metadata of events. Other attributes are sent as indexed fields by the event endpoint.
+var metadataKeys = map[string]string{
+	"com.splunk.index":      "index",
+	"com.splunk.host":       "host",
+	"com.splunk.source":     "source",
+	"com.splunk.sourcetype": "sourcetype",
+}
+
+// event is a HEC event.
+type event struct {
+	Time       *float64       `json:"time,omitempty"`
+	Host       string         `json:"host,omitempty"`
+	Source     string         `json:"source,omitempty"`
+	SourceType string         `json:"sourcetype,omitempty"`
+	Index      string         `json:"index,omitempty"`
+	Event      string         `json:"event"`
+	Fields     map[string]any `json:"fields,omitempty"`
+}
+
+// WriteEvents writes the log records as HEC events, one JSON object per line, as the event endpoint receives them:
+// with their time and metadata, and their other attributes as indexed fields.
+func WriteEvents(w io.Writer, ld plog.Logs) error {
+	enc := json.NewEncoder(w)
+	for _, rl := range ld.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, lr := range sl.LogRecords().All() {
+				ev := event{Event: lr.Body().AsString()}
+				t := lr.Timestamp()
+				if t == 0 {
+					t = lr.ObservedTimestamp()
+				}
+				if t != 0 {
+					seconds := float64(t.AsTime().UnixMilli()) / 1000
+					ev.Time = &seconds
+				}
+				for k, v := range lr.Attributes().All() {
+					switch metadataKeys[k] {
+					case "index":
+						ev.Index = v.AsString()
+					case "host":
+						ev.Host = v.AsString()
+					case "source":
+						ev.Source = v.AsString()
+					case "sourcetype":
+						ev.SourceType = v.AsString()
+					default:
+						if ev.Fields == nil {
+							ev.Fields = map[string]any{}
+						}
+						ev.Fields[k] = v.AsString()
+					}
+				}
+				if err := enc.Encode(ev); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// UnmarshalEvent sets lr to a HEC event written by WriteEvents.
+func UnmarshalEvent(data []byte, lr plog.LogRecord) error {
+	var ev event
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return err
+	}
+	lr.Body().SetStr(ev.Event)
+	if ev.Time != nil {
+		millis := int64(math.Round(*ev.Time * 1000))
+		lr.SetTimestamp(pcommon.NewTimestampFromTime(time.UnixMilli(millis)))
+	}
+	for attr, key := range metadataKeys {
+		var value string
+		switch key {
+		case "index":
+			value = ev.Index
+		case "host":
+			value = ev.Host
+		case "source":
+			value = ev.Source
+		case "sourcetype":
+			value = ev.SourceType
+		}
+		if value != "" {
+			lr.Attributes().PutStr(attr, value)
+		}
+	}
+	for k, v := range ev.Fields {
+		if s, ok := v.(string); ok {
+			lr.Attributes().PutStr(k, s)
+		} else {
+			_ = lr.Attributes().PutEmpty(k).FromRaw(v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package hecexporter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestUnmarshalEvent(t *testing.T) {
+	var b bytes.Buffer
+	ld := newLogs("app")
+	require.NoError(t, WriteEvents(&b, ld))
+
+	lr := plog.NewLogRecord()
+	require.NoError(t, UnmarshalEvent(b.Bytes(), lr))
+	want := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, want.Body().AsString(), lr.Body().AsString())
+	assert.Equal(t, want.Timestamp(), lr.Timestamp())
+	assert.Equal(t, want.Attributes().AsRaw(), lr.Attributes().AsRaw())
+
+	require.Error(t, UnmarshalEvent([]byte(`{"event":`), plog.NewLogRecord()))
+}
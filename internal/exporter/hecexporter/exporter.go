@@ -0,0 +1,247 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package hecexporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// hecExporter sends log records to HEC on a channel of its own, which the raw endpoint and acknowledgements require.
+type hecExporter struct {
+	cfg    *Config
+	set    component.TelemetrySettings
+	client *http.Client
+	// collectorURL is the URL of the collector endpoint, the parent of the raw, event and ack endpoints.
+	collectorURL *url.URL
+	channel      string
+}
+
+func newHECExporter(cfg *Config, set component.TelemetrySettings) *hecExporter {
+	return &hecExporter{cfg: cfg, set: set}
+}
+
+func (e *hecExporter) start(ctx context.Context, host component.Host) error {
+	u, err := url.Parse(e.cfg.ClientConfig.Endpoint)
+	if err != nil {
+		return err
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/event"), "/raw")
+	if path == "" {
+		path = "/services/collector"
+	}
+	u.Path = path
+	e.collectorURL = u
+	e.channel = uuid.NewString()
+	e.client, err = e.cfg.ClientConfig.ToClient(ctx, host.GetExtensions(), e.set)
+	return err
+}
+
+func (e *hecExporter) shutdown(context.Context) error {
+	if e.client != nil {
+		e.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// request is the body and query parameters of a request to the raw or event endpoint.
+type request struct {
+	query url.Values
+	body  bytes.Buffer
+}
+
+func (e *hecExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
+	endpoint := "event"
+	var requests []*request
+	if e.cfg.Raw {
+		endpoint = "raw"
+		requests = rawRequests(ld)
+	} else {
+		r, err := eventRequest(ld)
+		if err != nil {
+			return consumererror.NewPermanent(err)
+		}
+		requests = []*request{r}
+	}
+	var ackIDs []uint64
+	for _, r := range requests {
+		response, err := e.post(ctx, endpoint, r.query, r.body.Bytes())
+		if err != nil {
+			return err
+		}
+		if e.cfg.UseACK {
+			if response.AckID == nil {
+				return errors.New("no acknowledgement ID: enable indexer acknowledgement on the HEC token")
+			}
+			ackIDs = append(ackIDs, *response.AckID)
+		}
+	}
+	if !e.cfg.UseACK {
+		return nil
+	}
+	return e.waitForAcks(ctx, ackIDs)
+}
+
+// rawRequests returns a request per index, host, source and sourcetype of the log records, in their order,
+// setting these metadata as query parameters.
+func rawRequests(ld plog.Logs) []*request {
+	var requests []*request
+	byMetadata := map[string]*request{}
+	for _, rl := range ld.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, lr := range sl.LogRecords().All() {
+				query := url.Values{}
+				for attr, param := range metadataKeys {
+					if v, ok := lr.Attributes().Get(attr); ok && v.AsString() != "" {
+						query.Set(param, v.AsString())
+					}
+				}
+				key := query.Encode()
+				r, ok := byMetadata[key]
+				if !ok {
+					r = &request{query: query}
+					byMetadata[key] = r
+					requests = append(requests, r)
+				}
+				r.body.WriteString(strings.TrimRight(lr.Body().AsString(), "\n"))
+				r.body.WriteByte('\n')
+			}
+		}
+	}
+	return requests
+}
+
+// eventRequest returns a request sending the log records to the event endpoint.
+func eventRequest(ld plog.Logs) (*request, error) {
+	r := &request{}
+	if err := WriteEvents(&r.body, ld); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// response is the response of HEC to a request.
+type response struct {
+	Text  string  `json:"text"`
+	Code  int     `json:"code"`
+	AckID *uint64 `json:"ackId"`
+	// Acks are the acknowledgements of the ack endpoint, by ID.
+	Acks map[string]bool `json:"acks"`
+}
+
+// post sends a request to an endpoint under the collector endpoint, and returns its response.
+// Requests that HEC rejects as invalid are not retried.
+func (e *hecExporter) post(ctx context.Context, endpoint string, query url.Values, body []byte) (*response, error) {
+	u := *e.collectorURL
+	u.Path += "/" + endpoint
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("channel", e.channel)
+	u.RawQuery = query.Encode()
+
+	// Acknowledgement queries are small, and not compressed.
+	compress := !e.cfg.DisableCompression && endpoint != "ack"
+	var reader io.Reader = bytes.NewReader(body)
+	if compress {
+		var compressed bytes.Buffer
+		w := gzip.NewWriter(&compressed)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		reader = &compressed
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), reader)
+	if err != nil {
+		return nil, consumererror.NewPermanent(err)
+	}
+	req.Header.Set("Authorization", "Splunk "+string(e.cfg.Token))
+	req.Header.Set("X-Splunk-Request-Channel", e.channel)
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var r response
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 0 {
+		if err = json.Unmarshal(b, &r); err != nil && resp.StatusCode < 300 {
+			return nil, fmt.Errorf("invalid response from %s: %w", endpoint, err)
+		}
+	}
+	if resp.StatusCode >= 300 {
+		err = fmt.Errorf("HTTP %d from %s: %s", resp.StatusCode, endpoint, r.Text)
+		switch resp.StatusCode {
+		case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+			return nil, consumererror.NewPermanent(err)
+		}
+		return nil, err
+	}
+	return &r, nil
+}
+
+// waitForAcks queries the acknowledgement of ackIDs until indexers acknowledged all of them, or the context is done.
+func (e *hecExporter) waitForAcks(ctx context.Context, ackIDs []uint64) error {
+	pending := map[uint64]bool{}
+	for _, id := range ackIDs {
+		pending[id] = true
+	}
+	ticker := time.NewTicker(e.cfg.AckPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d request(s) not acknowledged: %w", len(pending), ctx.Err())
+		case <-ticker.C:
+		}
+		ids := make([]uint64, 0, len(pending))
+		for id := range pending {
+			ids = append(ids, id)
+		}
+		body, err := json.Marshal(map[string][]uint64{"acks": ids})
+		if err != nil {
+			return err
+		}
+		r, err := e.post(ctx, "ack", nil, body)
+		if err != nil && ctx.Err() != nil {
+			return fmt.Errorf("%d request(s) not acknowledged: %w", len(pending), ctx.Err())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query acknowledgements: %w", err)
+		}
+		for id := range pending {
+			if r.Acks[strconv.FormatUint(id, 10)] {
+				delete(pending, id)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+	}
+}
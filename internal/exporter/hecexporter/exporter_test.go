@@ -0,0 +1,199 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package hecexporter
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// hecServer is a fake HTTP Event Collector recording requests, and acknowledging them once acked is set.
+type hecServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+	acked    bool
+	withAck  bool
+	ackID    uint64
+}
+
+func startServer(t *testing.T, withAck bool) *hecServer {
+	s := &hecServer{withAck: withAck}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *hecServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Header.Get("Authorization") != "Splunk token" {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"text":"Invalid token","code":4}`))
+		return
+	}
+	if r.Header.Get("X-Splunk-Request-Channel") == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"text":"Data channel is missing","code":10}`))
+		return
+	}
+	body := r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = gz
+	}
+	b, _ := io.ReadAll(body)
+	if strings.HasSuffix(r.URL.Path, "/ack") {
+		var query struct{ Acks []uint64 }
+		_ = json.Unmarshal(b, &query)
+		acks := map[string]bool{}
+		for _, id := range query.Acks {
+			acks[fmt.Sprint(id)] = s.acked
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"acks": acks})
+		return
+	}
+	query := r.URL.Query()
+	query.Del("channel")
+	s.requests = append(s.requests, r.URL.Path+"?"+query.Encode()+"\n"+string(b))
+	if !s.withAck {
+		_, _ = w.Write([]byte(`{"text":"Success","code":0}`))
+		return
+	}
+	s.ackID++
+	_, _ = fmt.Fprintf(w, `{"text":"Success","code":0,"ackId":%d}`, s.ackID)
+}
+
+func (s *hecServer) ack() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acked = true
+}
+
+func (s *hecServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// startExporter starts an exporter sending synchronously to endpoint, without queue nor retries.
+func startExporter(t *testing.T, endpoint string, configure func(*Config)) exporter.Logs {
+	f := NewFactory()
+	cfg := f.CreateDefaultConfig().(*Config)
+	cfg.ClientConfig.Endpoint = endpoint
+	cfg.Token = "token"
+	cfg.QueueConfig = configoptional.None[exporterhelper.QueueBatchConfig]()
+	cfg.RetryConfig.Enabled = false
+	cfg.TimeoutConfig.Timeout = time.Second
+	cfg.AckPollInterval = 10 * time.Millisecond
+	if configure != nil {
+		configure(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+	e, err := f.CreateLogs(context.Background(), exporter.Settings{
+		ID:                component.NewID(componentType),
+		TelemetrySettings: componenttest.NewNopTelemetrySettings(),
+	}, cfg)
+	require.NoError(t, err)
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, e.Shutdown(context.Background()))
+	})
+	return e
+}
+
+func newLogs(sourceTypes ...string) plog.Logs {
+	ld := plog.NewLogs()
+	sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	for i, sourceType := range sourceTypes {
+		lr := sl.LogRecords().AppendEmpty()
+		lr.Body().SetStr(fmt.Sprintf("event %d\n", i))
+		lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 250000000)))
+		lr.Attributes().PutStr("com.splunk.index", "main")
+		lr.Attributes().PutStr("com.splunk.host", "web01")
+		lr.Attributes().PutStr("com.splunk.sourcetype", sourceType)
+		lr.Attributes().PutStr("env", "prod")
+	}
+	return ld
+}
+
+func TestSendRaw(t *testing.T) {
+	s := startServer(t, false)
+	e := startExporter(t, s.URL+"/services/collector", func(cfg *Config) {
+		cfg.Raw = true
+	})
+	require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("app", "db", "app")))
+	assert.Equal(t, []string{
+		"/services/collector/raw?host=web01&index=main&sourcetype=app\nevent 0\nevent 2\n",
+		"/services/collector/raw?host=web01&index=main&sourcetype=db\nevent 1\n",
+	}, s.received())
+}
+
+func TestSendEvents(t *testing.T) {
+	s := startServer(t, false)
+	e := startExporter(t, s.URL, func(cfg *Config) {
+		cfg.DisableCompression = true
+	})
+	require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("app")))
+	assert.Equal(t, []string{
+		"/services/collector/event?\n" +
+			`{"time":1700000000.25,"host":"web01","sourcetype":"app","index":"main","event":"event 0\n","fields":{"env":"prod"}}` + "\n",
+	}, s.received())
+}
+
+func TestUseACK(t *testing.T) {
+	s := startServer(t, true)
+	e := startExporter(t, s.URL+"/services/collector/raw", func(cfg *Config) {
+		cfg.Raw = true
+		cfg.UseACK = true
+		cfg.TimeoutConfig.Timeout = 200 * time.Millisecond
+	})
+	err := e.ConsumeLogs(context.Background(), newLogs("app", "db"))
+	require.ErrorContains(t, err, "2 request(s) not acknowledged")
+	assert.False(t, consumererror.IsPermanent(err))
+
+	s.ack()
+	require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("app")))
+	assert.Len(t, s.received(), 3)
+
+	s = startServer(t, false)
+	e = startExporter(t, s.URL, func(cfg *Config) {
+		cfg.UseACK = true
+	})
+	require.ErrorContains(t, e.ConsumeLogs(context.Background(), newLogs("app")), "no acknowledgement ID")
+}
+
+func TestInvalidToken(t *testing.T) {
+	s := startServer(t, false)
+	e := startExporter(t, s.URL, func(cfg *Config) {
+		cfg.Token = "other"
+	})
+	err := e.ConsumeLogs(context.Background(), newLogs("app"))
+	require.ErrorContains(t, err, "HTTP 403 from event: Invalid token")
+	assert.True(t, consumererror.IsPermanent(err))
+}
@@ -0,0 +1,62 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package s2sexporter
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+type Config struct {
+	TimeoutConfig exporterhelper.TimeoutConfig                             `mapstructure:",squash"`
+	QueueConfig   configoptional.Optional[exporterhelper.QueueBatchConfig] `mapstructure:"sending_queue"`
+	RetryConfig   configretry.BackOffConfig                                `mapstructure:"retry_on_failure"`
+
+	// Servers are the host:port addresses of the splunktcp inputs to send to.
+	Servers []string `mapstructure:"servers"`
+	// TLS sets the CA, the client certificate and the server name used to connect to the servers.
+	// Connections use TLS if UseTLS or one of these settings is set.
+	TLS configtls.ClientConfig `mapstructure:"tls"`
+	// UseTLS connects to the servers over TLS, verifying their certificate with the system CAs unless TLS sets a CA.
+	UseTLS bool `mapstructure:"use_tls"`
+	// SendCookedData sends events with their metadata over the S2S protocol.
+	// If false, only the raw text of events is sent, one event per line, to tcp inputs.
+	SendCookedData bool `mapstructure:"send_cooked_data"`
+	// UseACK waits for the indexer to acknowledge each batch of events, and sends the batch again otherwise.
+	UseACK bool `mapstructure:"use_ack"`
+	// AutoLBFrequency is how often the exporter switches to the next server. 0 keeps the connection until it fails.
+	AutoLBFrequency time.Duration `mapstructure:"auto_lb_frequency"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs []error
+	if len(cfg.Servers) == 0 {
+		errs = append(errs, errors.New("requires at least one server"))
+	}
+	for _, server := range cfg.Servers {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			errs = append(errs, fmt.Errorf("server %q is not a host:port address", server))
+		}
+	}
+	if cfg.UseACK && !cfg.SendCookedData {
+		errs = append(errs, errors.New("use_ack requires send_cooked_data"))
+	}
+	if cfg.AutoLBFrequency < 0 {
+		errs = append(errs, errors.New("auto_lb_frequency must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// useTLS returns true if connections use TLS.
+func (cfg *Config) useTLS() bool {
+	t := cfg.TLS
+	return cfg.UseTLS || t.CAFile != "" || t.CAPem != "" || t.CertFile != "" || t.CertPem != "" || t.InsecureSkipVerify || t.ServerName != ""
+}
@@ -0,0 +1,6 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package s2sexporter sends log records to the splunktcp inputs of Splunk indexers over the
+// Splunk-to-Splunk (S2S) protocol, like a forwarder.
+package s2sexporter
@@ -0,0 +1,322 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package s2sexporter
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/splunk/tarunner/internal/exporter/s2sexporter/internal/s2s"
+)
+
+// mgmtPort is the management port announced to indexers. The exporter does not listen on it.
+const mgmtPort = "8089"
+
+// Attributes holding the metadata of events. Other attributes are sent as indexed fields.
+var metadataKeys = map[string]string{
+	"com.splunk.index":      s2s.KeyIndex,
+	"com.splunk.host":       s2s.KeyHost,
+	"com.splunk.source":     s2s.KeySource,
+	"com.splunk.sourcetype": s2s.KeySourceType,
+}
+
+// s2sExporter sends log records to one server at a time, switching to the next server
+// on failure and every AutoLBFrequency.
+type s2sExporter struct {
+	cfg        *Config
+	logger     *zap.Logger
+	tlsConfig  *tls.Config
+	serverName string
+
+	mu sync.Mutex
+	// next is the index of the next server to connect to.
+	next int
+	conn *connection
+}
+
+// connection is a connection to a server, holding the state of the S2S session.
+type connection struct {
+	server      string
+	conn        net.Conn
+	r           *bufio.Reader
+	w           *bufio.Writer
+	connectedAt time.Time
+	// channels identify the streams of events on the connection, by source, host and sourcetype.
+	channels map[string]int
+	ackID    uint64
+}
+
+func newS2SExporter(cfg *Config, logger *zap.Logger) *s2sExporter {
+	return &s2sExporter{cfg: cfg, logger: logger}
+}
+
+func (e *s2sExporter) start(ctx context.Context, _ component.Host) error {
+	if e.cfg.useTLS() {
+		tlsConfig, err := e.cfg.TLS.LoadTLSConfig(ctx)
+		if err != nil {
+			return err
+		}
+		e.tlsConfig = tlsConfig
+	}
+	serverName, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	e.serverName = serverName
+	// Like forwarders, start with a random server to spread the load of several instances.
+	e.next = rand.IntN(len(e.cfg.Servers))
+	return nil
+}
+
+func (e *s2sExporter) shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.disconnect()
+	return nil
+}
+
+func (e *s2sExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn != nil && e.cfg.AutoLBFrequency > 0 && time.Since(e.conn.connectedAt) >= e.cfg.AutoLBFrequency {
+		e.disconnect()
+	}
+	if e.conn == nil {
+		if err := e.connect(ctx); err != nil {
+			return err
+		}
+	}
+	deadline, _ := ctx.Deadline()
+	if err := e.conn.conn.SetDeadline(deadline); err != nil {
+		e.disconnect()
+		return err
+	}
+	if err := e.send(ld); err != nil {
+		// The batch is retried on the next server.
+		server := e.conn.server
+		e.disconnect()
+		return fmt.Errorf("failed to send to %s: %w", server, err)
+	}
+	return nil
+}
+
+// connect connects to the next server accepting the connection.
+func (e *s2sExporter) connect(ctx context.Context) error {
+	var errs []error
+	for range e.cfg.Servers {
+		server := e.cfg.Servers[e.next]
+		e.next = (e.next + 1) % len(e.cfg.Servers)
+		c, err := e.dial(ctx, server)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", server, err))
+			continue
+		}
+		e.conn = c
+		e.logger.Debug("Connected to server", zap.String("server", server))
+		return nil
+	}
+	return fmt.Errorf("failed to connect to any server: %w", errors.Join(errs...))
+}
+
+func (e *s2sExporter) dial(ctx context.Context, server string) (*connection, error) {
+	var conn net.Conn
+	var err error
+	if e.tlsConfig != nil {
+		d := tls.Dialer{Config: e.tlsConfig}
+		conn, err = d.DialContext(ctx, "tcp", server)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", server)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &connection{
+		server:      server,
+		conn:        conn,
+		r:           bufio.NewReader(conn),
+		w:           bufio.NewWriter(conn),
+		connectedAt: time.Now(),
+		channels:    map[string]int{},
+	}
+	if e.cfg.SendCookedData {
+		deadline, _ := ctx.Deadline()
+		if err = conn.SetDeadline(deadline); err == nil {
+			err = e.handshake(c)
+		}
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// handshake sends the signature and the capabilities of the exporter, and checks the response of the server.
+func (e *s2sExporter) handshake(c *connection) error {
+	if err := s2s.WriteSignature(c.w, e.serverName, mgmtPort); err != nil {
+		return err
+	}
+	ack := "0"
+	if e.cfg.UseACK {
+		ack = "1"
+	}
+	capabilities := s2s.FormatControl(map[string]string{"ack": ack, "compression": "0"})
+	if err := s2s.WriteMessage(c.w, s2s.Message{{Key: s2s.KeyCapabilities, Value: capabilities}}); err != nil {
+		return err
+	}
+	if err := c.w.Flush(); err != nil {
+		return err
+	}
+	response, err := readControl(c.r)
+	if err != nil {
+		return fmt.Errorf("no capabilities response: %w", err)
+	}
+	if response["cap_response"] != "success" {
+		return fmt.Errorf("capabilities rejected: %s", response["cap_response"])
+	}
+	if e.cfg.UseACK && response["ack"] != "1" {
+		return errors.New("the server does not support acknowledgement")
+	}
+	return nil
+}
+
+// send writes the log records, and waits for their acknowledgement if enabled.
+func (e *s2sExporter) send(ld plog.Logs) error {
+	c := e.conn
+	for _, rl := range ld.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, lr := range sl.LogRecords().All() {
+				var err error
+				if e.cfg.SendCookedData {
+					err = s2s.WriteMessage(c.w, c.event(lr))
+				} else {
+					_, err = c.w.WriteString(strings.TrimRight(lr.Body().AsString(), "\n") + "\n")
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if !e.cfg.UseACK {
+		return c.w.Flush()
+	}
+	c.ackID++
+	request := s2s.FormatControl(map[string]string{"ack_req": strconv.FormatUint(c.ackID, 10)})
+	if err := s2s.WriteMessage(c.w, s2s.Message{{Key: s2s.KeyControl, Value: request}}); err != nil {
+		return err
+	}
+	if err := c.w.Flush(); err != nil {
+		return err
+	}
+	for {
+		response, err := readControl(c.r)
+		if err != nil {
+			return fmt.Errorf("no acknowledgement: %w", err)
+		}
+		if response["ack"] == strconv.FormatUint(c.ackID, 10) {
+			return nil
+		}
+	}
+}
+
+// event returns the message of a log record: its raw text, time, metadata and channel,
+// and its other attributes as indexed fields.
+func (c *connection) event(lr plog.LogRecord) s2s.Message {
+	m := s2s.Message{{Key: s2s.KeyRaw, Value: lr.Body().AsString()}}
+	t := lr.Timestamp()
+	if t == 0 {
+		t = lr.ObservedTimestamp()
+	}
+	if t != 0 {
+		m = append(m, s2s.Pair{Key: s2s.KeyTime, Value: strconv.FormatInt(t.AsTime().Unix(), 10)})
+		if nanos := t.AsTime().Nanosecond(); nanos != 0 {
+			m = append(m, s2s.Pair{Key: s2s.KeySubsecond, Value: strings.TrimRight(fmt.Sprintf(".%09d", nanos), "0")})
+		}
+	}
+	var fields []string
+	for k, v := range lr.Attributes().All() {
+		key, ok := metadataKeys[k]
+		switch {
+		case !ok:
+			fields = append(fields, k+"::"+quoteMeta(v.AsString()))
+		case key == s2s.KeyIndex:
+			m = append(m, s2s.Pair{Key: key, Value: v.AsString()})
+		default:
+			// MetaData keys are prefixed with the name of the field.
+			m = append(m, s2s.Pair{Key: key, Value: strings.TrimPrefix(k, "com.splunk.") + "::" + v.AsString()})
+		}
+	}
+	if len(fields) > 0 {
+		sort.Strings(fields)
+		m = append(m, s2s.Pair{Key: s2s.KeyMeta, Value: strings.Join(fields, " ")})
+	}
+	m = append(m, s2s.Pair{Key: s2s.KeyChannel, Value: strconv.Itoa(c.channel(lr.Attributes()))})
+	return m
+}
+
+// quoteMeta quotes the value of an indexed field of _meta like Splunk does, escaping its quotes and backslashes,
+// so values with spaces are not split into several fields.
+func quoteMeta(value string) string {
+	return `"` + metaEscaper.Replace(value) + `"`
+}
+
+var metaEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// channel returns the channel of the stream of events sharing the source, host and sourcetype of attributes.
+func (c *connection) channel(attributes pcommon.Map) int {
+	var key strings.Builder
+	for _, name := range []string{"com.splunk.source", "com.splunk.host", "com.splunk.sourcetype"} {
+		if v, ok := attributes.Get(name); ok {
+			key.WriteString(v.AsString())
+		}
+		key.WriteByte(0)
+	}
+	id, ok := c.channels[key.String()]
+	if !ok {
+		id = len(c.channels) + 1
+		c.channels[key.String()] = id
+	}
+	return id
+}
+
+// disconnect closes the connection to the current server, if any.
+func (e *s2sExporter) disconnect() {
+	if e.conn == nil {
+		return
+	}
+	_ = e.conn.conn.Close()
+	e.conn = nil
+}
+
+// readControl reads messages until a control message, and returns its settings.
+func readControl(r *bufio.Reader) (map[string]string, error) {
+	for {
+		m, err := s2s.ReadMessage(r)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := m.Get(s2s.KeyControl); ok {
+			return s2s.ParseControl(value), nil
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package s2sexporter
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/splunk/tarunner/internal/exporter/s2sexporter/s2stest"
+)
+
+// startExporter starts an exporter sending synchronously to servers, without queue nor retries.
+func startExporter(t *testing.T, servers []string, configure func(*Config)) exporter.Logs {
+	f := NewFactory()
+	cfg := f.CreateDefaultConfig().(*Config)
+	cfg.Servers = servers
+	cfg.QueueConfig = configoptional.None[exporterhelper.QueueBatchConfig]()
+	cfg.RetryConfig.Enabled = false
+	cfg.TimeoutConfig.Timeout = time.Second
+	if configure != nil {
+		configure(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+	e, err := f.CreateLogs(context.Background(), exporter.Settings{
+		ID:                component.NewID(componentType),
+		TelemetrySettings: componenttest.NewNopTelemetrySettings(),
+	}, cfg)
+	require.NoError(t, err)
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, e.Shutdown(context.Background()))
+	})
+	return e
+}
+
+func startServer(t *testing.T, opts ...s2stest.Option) *s2stest.Server {
+	s, err := s2stest.NewServer(opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return s
+}
+
+func newLogs(bodies ...string) plog.Logs {
+	ld := plog.NewLogs()
+	sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	for _, body := range bodies {
+		lr := sl.LogRecords().AppendEmpty()
+		lr.Body().SetStr(body)
+		lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 250000000)))
+		lr.Attributes().PutStr("com.splunk.index", "main")
+		lr.Attributes().PutStr("com.splunk.host", "web01")
+		lr.Attributes().PutStr("com.splunk.source", "/var/log/app.log")
+		lr.Attributes().PutStr("com.splunk.sourcetype", "app")
+		lr.Attributes().PutStr("env", "prod")
+	}
+	return ld
+}
+
+func TestSendCookedData(t *testing.T) {
+	s := startServer(t)
+	e := startExporter(t, []string{s.Addr()}, nil)
+
+	require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("first", "second")))
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		assert.Len(tt, s.Events(), 2)
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, s2stest.Event{
+		"_raw":                "first",
+		"_time":               "1700000000",
+		"_subsecond":          ".25",
+		"_MetaData:Index":     "main",
+		"MetaData:Host":       "host::web01",
+		"MetaData:Source":     "source::/var/log/app.log",
+		"MetaData:Sourcetype": "sourcetype::app",
+		"_meta":               `env::"prod"`,
+		"_channel":            "1",
+	}, s.Events()[0])
+	assert.Equal(t, "second", s.Events()[1]["_raw"])
+}
+
+func TestIndexedFieldsQuoted(t *testing.T) {
+	s := startServer(t)
+	e := startExporter(t, []string{s.Addr()}, nil)
+
+	ld := newLogs("first")
+	attributes := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	attributes.PutStr("env", "prod east")
+	attributes.PutStr("path", `C:\logs\"app".log`)
+	require.NoError(t, e.ConsumeLogs(context.Background(), ld))
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		assert.Len(tt, s.Events(), 1)
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, `env::"prod east" path::"C:\\logs\\\"app\".log"`, s.Events()[0]["_meta"])
+}
+
+func TestSendUncookedData(t *testing.T) {
+	s := startServer(t)
+	e := startExporter(t, []string{s.Addr()}, func(cfg *Config) {
+		cfg.SendCookedData = false
+	})
+
+	require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("first", "second\n")))
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		assert.Equal(tt, []s2stest.Event{{"_raw": "first"}, {"_raw": "second"}}, s.Events())
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestUseACK(t *testing.T) {
+	s := startServer(t)
+	e := startExporter(t, []string{s.Addr()}, func(cfg *Config) {
+		cfg.UseACK = true
+	})
+	require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("first")))
+	require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("second")))
+	assert.Len(t, s.Events(), 2)
+
+	s = startServer(t, s2stest.WithDroppedAcks())
+	e = startExporter(t, []string{s.Addr()}, func(cfg *Config) {
+		cfg.UseACK = true
+		cfg.TimeoutConfig.Timeout = 200 * time.Millisecond
+	})
+	require.ErrorContains(t, e.ConsumeLogs(context.Background(), newLogs("first")), "no acknowledgement")
+
+	s = startServer(t, s2stest.WithoutAck())
+	e = startExporter(t, []string{s.Addr()}, func(cfg *Config) {
+		cfg.UseACK = true
+	})
+	require.ErrorContains(t, e.ConsumeLogs(context.Background(), newLogs("first")), "the server does not support acknowledgement")
+}
+
+func TestUseTLS(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer func() {
+		_ = l.Close()
+	}()
+	firstByte := make(chan byte, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		b := make([]byte, 1)
+		if _, err := io.ReadFull(conn, b); err == nil {
+			firstByte <- b[0]
+		}
+	}()
+
+	// Without a CA nor a certificate, use_tls connects over TLS verifying the server with the system CAs.
+	e := startExporter(t, []string{l.Addr().String()}, func(cfg *Config) {
+		cfg.UseTLS = true
+	})
+	require.Error(t, e.ConsumeLogs(context.Background(), newLogs("first")))
+	select {
+	case b := <-firstByte:
+		// TLS records of the handshake start with the content type 22.
+		assert.Equal(t, byte(22), b)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the exporter did not connect")
+	}
+}
+
+func TestLoadBalancing(t *testing.T) {
+	s1 := startServer(t)
+	s2 := startServer(t)
+	e := startExporter(t, []string{s1.Addr(), s2.Addr()}, func(cfg *Config) {
+		cfg.UseACK = true
+		cfg.AutoLBFrequency = time.Nanosecond
+	})
+	for range 4 {
+		require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("event")))
+	}
+	// each batch is sent on a new connection, to the next server.
+	assert.Len(t, s1.Events(), 2)
+	assert.Len(t, s2.Events(), 2)
+	assert.Equal(t, 2, s1.Connections())
+	assert.Equal(t, 2, s2.Connections())
+}
+
+func TestFailover(t *testing.T) {
+	down := startServer(t)
+	require.NoError(t, down.Close())
+	s := startServer(t)
+	e := startExporter(t, []string{down.Addr(), s.Addr()}, func(cfg *Config) {
+		cfg.UseACK = true
+	})
+	for range 3 {
+		require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("event")))
+	}
+	assert.Len(t, s.Events(), 3)
+	assert.Equal(t, 1, s.Connections())
+
+	require.NoError(t, s.Close())
+	require.ErrorContains(t, e.ConsumeLogs(context.Background(), newLogs("event")), "failed to")
+}
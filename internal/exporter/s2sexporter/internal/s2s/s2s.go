@@ -0,0 +1,221 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package s2s encodes and decodes the messages of the Splunk-to-Splunk (S2S) protocol spoken by forwarders
+// to the splunktcp inputs of indexers.
+//
+// A connection starts with a signature of fixed size, followed by messages. Each message is a list of
+// key/value pairs:
+//
+//	uint32 size | uint32 count | count × (string key, string value) | uint32 0 | string "_raw"
+//
+// where size is the size of the rest of the message, and each string is a uint32 length, counting a
+// trailing NUL byte, followed by the bytes of the string and the NUL byte. Integers are big endian.
+package s2s
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Signature is the protocol version sent by forwarders at the start of a connection.
+const Signature = "--splunk-cooked-mode-v3--"
+
+// Sizes of the fields of the signature header.
+const (
+	signatureSize  = 128
+	serverNameSize = 256
+	mgmtPortSize   = 16
+
+	// SignatureHeaderSize is the size of the header starting a connection.
+	SignatureHeaderSize = signatureSize + serverNameSize + mgmtPortSize
+)
+
+// maxMessageSize bounds the size of the messages read, to fail on corrupt streams.
+const maxMessageSize = 64 << 20
+
+// Keys of the messages.
+const (
+	KeyRaw        = "_raw"
+	KeyTime       = "_time"
+	KeySubsecond  = "_subsecond"
+	KeyIndex      = "_MetaData:Index"
+	KeyHost       = "MetaData:Host"
+	KeySource     = "MetaData:Source"
+	KeySourceType = "MetaData:Sourcetype"
+	KeyChannel    = "_channel"
+	// KeyMeta holds the indexed fields of the event, as space-separated field::value pairs.
+	KeyMeta = "_meta"
+	// KeyCapabilities is the key of the message negotiating the capabilities of the connection,
+	// sent by forwarders after the signature.
+	KeyCapabilities = "__s2s_capabilities"
+	// KeyControl is the key of control messages, such as the response of indexers to the capabilities
+	// of the forwarder, acknowledgement requests and acknowledgements.
+	KeyControl = "__s2s_control_msg"
+)
+
+// Message is a list of key/value pairs.
+type Message []Pair
+
+// Pair is a key/value pair of a message.
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// Get returns the value of the first pair of the message with the given key.
+func (m Message) Get(key string) (string, bool) {
+	for _, p := range m {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// WriteSignature writes the header starting a connection, naming the forwarder and its management port.
+func WriteSignature(w io.Writer, serverName string, mgmtPort string) error {
+	header := make([]byte, SignatureHeaderSize)
+	copy(header[:signatureSize], Signature)
+	copy(header[signatureSize:signatureSize+serverNameSize-1], serverName)
+	copy(header[signatureSize+serverNameSize:SignatureHeaderSize-1], mgmtPort)
+	_, err := w.Write(header)
+	return err
+}
+
+// ReadSignature reads the header starting a connection, and returns the name of the forwarder.
+func ReadSignature(r io.Reader) (string, error) {
+	header := make([]byte, SignatureHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return "", err
+	}
+	if signature := cString(header[:signatureSize]); signature != Signature {
+		return "", fmt.Errorf("unsupported signature %q", signature)
+	}
+	return cString(header[signatureSize : signatureSize+serverNameSize]), nil
+}
+
+// WriteMessage writes a message.
+func WriteMessage(w io.Writer, m Message) error {
+	var body bytes.Buffer
+	writeUint32(&body, uint32(len(m)))
+	for _, p := range m {
+		writeString(&body, p.Key)
+		writeString(&body, p.Value)
+	}
+	writeUint32(&body, 0)
+	writeString(&body, KeyRaw)
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(body.Len()))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(body.Bytes())
+	return err
+}
+
+// ReadMessage reads a message.
+func ReadMessage(r *bufio.Reader) (Message, error) {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if size > maxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the maximum size", size)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	br := bytes.NewReader(body)
+	var count uint32
+	if err := binary.Read(br, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+	m := make(Message, 0, min(count, 64))
+	for range count {
+		key, err := readString(br)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readString(br)
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, Pair{Key: key, Value: value})
+	}
+	var zero uint32
+	if err := binary.Read(br, binary.BigEndian, &zero); err != nil {
+		return nil, err
+	}
+	if trailer, err := readString(br); err != nil || trailer != KeyRaw {
+		return nil, errors.New("invalid message trailer")
+	}
+	return m, nil
+}
+
+// FormatControl formats the value of a control or capabilities message from its settings,
+// as semicolon-separated key=value pairs sorted by key.
+func FormatControl(settings map[string]string) string {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+settings[k])
+	}
+	return strings.Join(pairs, ";")
+}
+
+// ParseControl parses the value of a control or capabilities message.
+func ParseControl(value string) map[string]string {
+	settings := map[string]string{}
+	for pair := range strings.SplitSeq(value, ";") {
+		if k, v, ok := strings.Cut(pair, "="); ok {
+			settings[k] = v
+		}
+	}
+	return settings
+}
+
+func writeUint32(b *bytes.Buffer, v uint32) {
+	_ = binary.Write(b, binary.BigEndian, v)
+}
+
+func writeString(b *bytes.Buffer, s string) {
+	writeUint32(b, uint32(len(s)+1))
+	b.WriteString(s)
+	b.WriteByte(0)
+}
+
+func readString(r *bytes.Reader) (string, error) {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return "", err
+	}
+	if size == 0 || int64(size) > int64(r.Len()) {
+		return "", fmt.Errorf("invalid string size %d", size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b[:size-1]), nil
+}
+
+// cString returns the string held by a NUL-padded field.
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
@@ -0,0 +1,39 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package s2s
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessages(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, WriteSignature(&b, "forwarder", "8089"))
+	assert.Equal(t, SignatureHeaderSize, b.Len())
+	m := Message{{Key: KeyRaw, Value: "hello world"}, {Key: KeyIndex, Value: "main"}}
+	require.NoError(t, WriteMessage(&b, m))
+	require.NoError(t, WriteMessage(&b, Message{{Key: KeyControl, Value: FormatControl(map[string]string{"cap_response": "success", "ack": "1"})}}))
+
+	r := bufio.NewReader(&b)
+	serverName, err := ReadSignature(r)
+	require.NoError(t, err)
+	assert.Equal(t, "forwarder", serverName)
+	read, err := ReadMessage(r)
+	require.NoError(t, err)
+	assert.Equal(t, m, read)
+	read, err = ReadMessage(r)
+	require.NoError(t, err)
+	value, ok := read.Get(KeyControl)
+	require.True(t, ok)
+	assert.Equal(t, "ack=1;cap_response=success", value)
+	assert.Equal(t, map[string]string{"ack": "1", "cap_response": "success"}, ParseControl(value))
+
+	_, err = ReadSignature(bytes.NewReader(make([]byte, SignatureHeaderSize)))
+	require.EqualError(t, err, `unsupported signature ""`)
+}
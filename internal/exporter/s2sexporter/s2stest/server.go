@@ -0,0 +1,198 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package s2stest provides an in-process S2S listener recording the events it receives, to test forwarding.
+package s2stest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/splunk/tarunner/internal/exporter/s2sexporter/internal/s2s"
+)
+
+// Event holds the keys and values of an event received by the server.
+// Events sent uncooked only have a _raw key.
+type Event map[string]string
+
+// Option customizes the server.
+type Option func(*Server)
+
+// WithoutAck makes the server refuse indexer acknowledgement.
+func WithoutAck() Option {
+	return func(s *Server) {
+		s.ackSupported = false
+	}
+}
+
+// WithDroppedAcks makes the server accept indexer acknowledgement, but never acknowledge events.
+func WithDroppedAcks() Option {
+	return func(s *Server) {
+		s.dropAcks = true
+	}
+}
+
+// Server is a splunktcp input accepting cooked connections of forwarders, and tcp connections of raw data.
+type Server struct {
+	listener     net.Listener
+	ackSupported bool
+	dropAcks     bool
+
+	mu          sync.Mutex
+	events      []Event
+	connections int
+	conns       map[net.Conn]struct{}
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+// NewServer starts a server listening on a random local port.
+func NewServer(opts ...Option) (*Server, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{listener: l, ackSupported: true, conns: map[net.Conn]struct{}{}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.wg.Go(s.accept)
+	return s, nil
+}
+
+// Addr returns the host:port address of the server.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Events returns the events received so far.
+func (s *Server) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := make([]Event, len(s.events))
+	for i, e := range s.events {
+		events[i] = maps.Clone(e)
+	}
+	return events
+}
+
+// Connections returns the number of connections accepted so far.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// Close stops the server, closing all connections.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.connections++
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Go(func() {
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+				_ = conn.Close()
+			}()
+			_ = s.serve(conn)
+		})
+	}
+}
+
+// serve reads a connection until it is closed. Connections not starting with the signature of forwarders carry raw data.
+func (s *Server) serve(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+	// Only wait for a full signature if the connection may start with one.
+	start, err := r.Peek(1)
+	if err == nil && start[0] == s2s.Signature[0] {
+		start, err = r.Peek(len(s2s.Signature))
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if !bytes.Equal(start, []byte(s2s.Signature)) {
+		return s.serveRaw(r)
+	}
+	if _, err = s2s.ReadSignature(r); err != nil {
+		return err
+	}
+	ack := false
+	for {
+		m, err := s2s.ReadMessage(r)
+		if err != nil {
+			return err
+		}
+		if value, ok := m.Get(s2s.KeyCapabilities); ok {
+			ack = s.ackSupported && s2s.ParseControl(value)["ack"] == "1"
+			response := map[string]string{"cap_response": "success", "ack": "0"}
+			if ack {
+				response["ack"] = "1"
+			}
+			if err = s2s.WriteMessage(conn, s2s.Message{{Key: s2s.KeyControl, Value: s2s.FormatControl(response)}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if value, ok := m.Get(s2s.KeyControl); ok {
+			id, requested := s2s.ParseControl(value)["ack_req"]
+			if !requested || !ack || s.dropAcks {
+				continue
+			}
+			if err = s2s.WriteMessage(conn, s2s.Message{{Key: s2s.KeyControl, Value: s2s.FormatControl(map[string]string{"ack": id})}}); err != nil {
+				return err
+			}
+			continue
+		}
+		e := Event{}
+		for _, p := range m {
+			e[p.Key] = p.Value
+		}
+		s.mu.Lock()
+		s.events = append(s.events, e)
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) serveRaw(r *bufio.Reader) error {
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			s.mu.Lock()
+			s.events = append(s.events, Event{s2s.KeyRaw: strings.TrimSuffix(line, "\n")})
+			s.mu.Unlock()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
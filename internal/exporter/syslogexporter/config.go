@@ -0,0 +1,107 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package syslogexporter
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+// Protocols of the connection to the syslog server.
+const (
+	ProtocolUDP = "udp"
+	ProtocolTCP = "tcp"
+	ProtocolTLS = "tls"
+)
+
+// Formats of syslog messages.
+const (
+	FormatRFC3164 = "rfc3164"
+	FormatRFC5424 = "rfc5424"
+)
+
+// noPriority omits the priority of RFC 3164 messages, as the NO_PRI priority of outputs.conf.
+const noPriority = "NO_PRI"
+
+type Config struct {
+	TimeoutConfig exporterhelper.TimeoutConfig                             `mapstructure:",squash"`
+	QueueConfig   configoptional.Optional[exporterhelper.QueueBatchConfig] `mapstructure:"sending_queue"`
+	RetryConfig   configretry.BackOffConfig                                `mapstructure:"retry_on_failure"`
+
+	// Endpoint is the host:port address of the syslog server.
+	Endpoint string `mapstructure:"endpoint"`
+	// Protocol is udp, tcp, or tls.
+	Protocol string `mapstructure:"protocol"`
+	// TLS sets the CA, the client certificate and the server name used with the tls protocol.
+	TLS configtls.ClientConfig `mapstructure:"tls"`
+	// Format is rfc3164 or rfc5424.
+	Format string `mapstructure:"format"`
+	// Priority is the priority of messages, such as <13>, or NO_PRI to omit it from RFC 3164 messages.
+	Priority string `mapstructure:"priority"`
+	// TimestampFormat is the strftime format of the timestamp of RFC 3164 messages, such as %b %e %H:%M:%S.
+	// RFC 3164 messages have no timestamp if it is not set, RFC 5424 messages always have an RFC 3339 timestamp.
+	TimestampFormat string `mapstructure:"timestamp_format"`
+	// SyslogSourceType is the sourcetype of events already formatted as syslog messages, sent as is.
+	SyslogSourceType string `mapstructure:"syslog_sourcetype"`
+	// MaxEventSize is the maximum size of messages in bytes. Longer messages are truncated.
+	MaxEventSize int `mapstructure:"max_event_size"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs []error
+	if _, _, err := net.SplitHostPort(cfg.Endpoint); err != nil {
+		errs = append(errs, fmt.Errorf("endpoint %q is not a host:port address", cfg.Endpoint))
+	}
+	switch cfg.Protocol {
+	case ProtocolUDP, ProtocolTCP, ProtocolTLS:
+	default:
+		errs = append(errs, fmt.Errorf("protocol %q is not supported, use udp, tcp or tls", cfg.Protocol))
+	}
+	switch cfg.Format {
+	case FormatRFC3164:
+	case FormatRFC5424:
+		if cfg.Priority == noPriority {
+			errs = append(errs, errors.New("priority NO_PRI is not supported by rfc5424"))
+		}
+		if cfg.TimestampFormat != "" {
+			errs = append(errs, errors.New("timestamp_format is not supported by rfc5424, messages have RFC 3339 timestamps"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("format %q is not supported, use rfc3164 or rfc5424", cfg.Format))
+	}
+	if _, err := parsePriority(cfg.Priority); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateStrftime(cfg.TimestampFormat); err != nil {
+		errs = append(errs, fmt.Errorf("timestamp_format: %w", err))
+	}
+	if cfg.MaxEventSize <= 0 {
+		errs = append(errs, errors.New("max_event_size must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// parsePriority returns the header of messages set by a priority, such as <13>, or an empty header for NO_PRI.
+func parsePriority(priority string) (string, error) {
+	if priority == noPriority {
+		return "", nil
+	}
+	value, ok := strings.CutPrefix(priority, "<")
+	if ok {
+		value, ok = strings.CutSuffix(value, ">")
+	}
+	n, err := strconv.Atoi(value)
+	if !ok || err != nil || n < 0 || n > 191 {
+		return "", fmt.Errorf("priority %q must be <0> to <191>, or NO_PRI", priority)
+	}
+	return "<" + strconv.Itoa(n) + ">", nil
+}
@@ -0,0 +1,6 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package syslogexporter sends log records to a syslog server as RFC 3164 or RFC 5424 messages,
+// over UDP, TCP or TLS, like the syslog outputs of Splunk forwarders.
+package syslogexporter
@@ -0,0 +1,158 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package syslogexporter
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// syslogExporter sends log records to a syslog server, one message per UDP datagram,
+// or over a TCP connection kept until it fails.
+type syslogExporter struct {
+	cfg       *Config
+	logger    *zap.Logger
+	tlsConfig *tls.Config
+	formatter *formatter
+
+	mu   sync.Mutex
+	conn net.Conn
+	w    *bufio.Writer
+}
+
+func newSyslogExporter(cfg *Config, logger *zap.Logger) *syslogExporter {
+	return &syslogExporter{cfg: cfg, logger: logger}
+}
+
+func (e *syslogExporter) start(ctx context.Context, _ component.Host) error {
+	if e.cfg.Protocol == ProtocolTLS {
+		tlsConfig, err := e.cfg.TLS.LoadTLSConfig(ctx)
+		if err != nil {
+			return err
+		}
+		e.tlsConfig = tlsConfig
+	}
+	priority, err := parsePriority(e.cfg.Priority)
+	if err != nil {
+		return err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	e.formatter = &formatter{
+		format:           e.cfg.Format,
+		priority:         priority,
+		timestampFormat:  e.cfg.TimestampFormat,
+		syslogSourceType: e.cfg.SyslogSourceType,
+		maxEventSize:     e.cfg.MaxEventSize,
+		hostname:         hostname,
+		location:         time.Local,
+	}
+	return nil
+}
+
+func (e *syslogExporter) shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.disconnect()
+	return nil
+}
+
+func (e *syslogExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn == nil {
+		if err := e.connect(ctx); err != nil {
+			return fmt.Errorf("failed to connect to %s: %w", e.cfg.Endpoint, err)
+		}
+	}
+	deadline, _ := ctx.Deadline()
+	if err := e.conn.SetDeadline(deadline); err != nil {
+		e.disconnect()
+		return err
+	}
+	if err := e.send(ld); err != nil {
+		// The batch is retried on a new connection.
+		e.disconnect()
+		return fmt.Errorf("failed to send to %s: %w", e.cfg.Endpoint, err)
+	}
+	return nil
+}
+
+func (e *syslogExporter) connect(ctx context.Context) error {
+	var conn net.Conn
+	var err error
+	switch e.cfg.Protocol {
+	case ProtocolUDP:
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "udp", e.cfg.Endpoint)
+	case ProtocolTLS:
+		d := tls.Dialer{Config: e.tlsConfig}
+		conn, err = d.DialContext(ctx, "tcp", e.cfg.Endpoint)
+	default:
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", e.cfg.Endpoint)
+	}
+	if err != nil {
+		return err
+	}
+	e.conn = conn
+	e.w = bufio.NewWriter(conn)
+	e.logger.Debug("Connected to syslog server", zap.String("endpoint", e.cfg.Endpoint))
+	return nil
+}
+
+// send writes a message per log record. Over TCP, RFC 5424 messages are framed by their length (RFC 6587 octet counting),
+// and RFC 3164 messages are terminated by a newline, the newlines of multi-line messages being escaped as #012.
+func (e *syslogExporter) send(ld plog.Logs) error {
+	for _, rl := range ld.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, lr := range sl.LogRecords().All() {
+				message := e.formatter.message(lr)
+				var err error
+				switch {
+				case e.cfg.Protocol == ProtocolUDP:
+					// Each message is a datagram.
+					_, err = e.conn.Write([]byte(message))
+				case e.cfg.Format == FormatRFC5424:
+					_, err = e.w.WriteString(strconv.Itoa(len(message)) + " " + message)
+				default:
+					// The newlines of a message would split it into several messages: they are escaped like rsyslog escapes control characters.
+					_, err = e.w.WriteString(strings.ReplaceAll(message, "\n", "#012") + "\n")
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if e.cfg.Protocol == ProtocolUDP {
+		return nil
+	}
+	return e.w.Flush()
+}
+
+// disconnect closes the connection to the server, if any.
+func (e *syslogExporter) disconnect() {
+	if e.conn == nil {
+		return
+	}
+	_ = e.conn.Close()
+	e.conn = nil
+	e.w = nil
+}
@@ -0,0 +1,145 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package syslogexporter
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// startExporter starts an exporter sending synchronously to endpoint, without queue nor retries.
+func startExporter(t *testing.T, endpoint string, configure func(*Config)) exporter.Logs {
+	f := NewFactory()
+	cfg := f.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = endpoint
+	cfg.QueueConfig = configoptional.None[exporterhelper.QueueBatchConfig]()
+	cfg.RetryConfig.Enabled = false
+	cfg.TimeoutConfig.Timeout = time.Second
+	if configure != nil {
+		configure(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+	e, err := f.CreateLogs(context.Background(), exporter.Settings{
+		ID:                component.NewID(componentType),
+		TelemetrySettings: componenttest.NewNopTelemetrySettings(),
+	}, cfg)
+	require.NoError(t, err)
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, e.Shutdown(context.Background()))
+	})
+	return e
+}
+
+func newLogs(bodies ...string) plog.Logs {
+	ld := plog.NewLogs()
+	sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	for _, body := range bodies {
+		newLogRecord(body).CopyTo(sl.LogRecords().AppendEmpty())
+	}
+	return ld
+}
+
+func TestSendUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	e := startExporter(t, conn.LocalAddr().String(), nil)
+
+	require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("first", "second")))
+	buf := make([]byte, 1024)
+	for _, expected := range []string{"<13>web01 linux_secure: first", "<13>web01 linux_secure: second"} {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(buf[:n]))
+	}
+}
+
+// listenTCP accepts a connection and returns the data it reads, once the connection is closed.
+func listenTCP(t *testing.T) (string, <-chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+	received := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		defer conn.Close()
+		var data []byte
+		r := bufio.NewReader(conn)
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				break
+			}
+			data = append(data, b)
+		}
+		received <- string(data)
+	}()
+	return l.Addr().String(), received
+}
+
+func TestSendTCP(t *testing.T) {
+	addr, received := listenTCP(t)
+	e := startExporter(t, addr, func(cfg *Config) {
+		cfg.Protocol = ProtocolTCP
+	})
+	require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("first", "second", "multi\nline\n")))
+	require.NoError(t, e.Shutdown(context.Background()))
+	assert.Equal(t, "<13>web01 linux_secure: first\n<13>web01 linux_secure: second\n<13>web01 linux_secure: multi#012line\n", <-received)
+
+	addr, received = listenTCP(t)
+	e = startExporter(t, addr, func(cfg *Config) {
+		cfg.Protocol = ProtocolTCP
+		cfg.Format = FormatRFC5424
+	})
+	require.NoError(t, e.ConsumeLogs(context.Background(), newLogs("multi\nline")))
+	require.NoError(t, e.Shutdown(context.Background()))
+	assert.Equal(t, "69 <13>1 2023-11-04T22:13:20.250000Z web01 linux:secure - - - multi\nline", <-received)
+}
+
+func TestSendFailure(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+	e := startExporter(t, l.Addr().String(), func(cfg *Config) {
+		cfg.Protocol = ProtocolTLS
+	})
+	require.ErrorContains(t, e.ConsumeLogs(context.Background(), newLogs("first")), "failed to connect to "+l.Addr().String())
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "siem"
+	cfg.Protocol = "quic"
+	cfg.Format = FormatRFC5424
+	cfg.Priority = "NO_PRI"
+	cfg.TimestampFormat = "%Q"
+	cfg.MaxEventSize = 0
+	err := cfg.Validate()
+	require.ErrorContains(t, err, `endpoint "siem" is not a host:port address`)
+	require.ErrorContains(t, err, `protocol "quic" is not supported, use udp, tcp or tls`)
+	require.ErrorContains(t, err, "priority NO_PRI is not supported by rfc5424")
+	require.ErrorContains(t, err, "timestamp_format is not supported by rfc5424")
+	require.ErrorContains(t, err, "timestamp_format: unsupported directive %Q")
+	require.ErrorContains(t, err, "max_event_size must be positive")
+}
@@ -0,0 +1,193 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package syslogexporter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Maximum lengths of the hostname and the application name of messages.
+const (
+	maxHostnameLen    = 255
+	maxAppNameLen5424 = 48
+	maxTagLen3164     = 32
+)
+
+// rfc5424Time is the layout of the timestamps of RFC 5424 messages.
+const rfc5424Time = "2006-01-02T15:04:05.000000Z07:00"
+
+// formatter formats log records as syslog messages.
+type formatter struct {
+	format           string
+	priority         string
+	timestampFormat  string
+	syslogSourceType string
+	maxEventSize     int
+	// hostname is the hostname of messages of log records without a com.splunk.host attribute.
+	hostname string
+	// location is the time zone of RFC 3164 timestamps.
+	location *time.Location
+}
+
+// message returns the syslog message of a log record. Its hostname is the com.splunk.host attribute,
+// and its application name, or tag, the com.splunk.sourcetype attribute.
+func (f *formatter) message(lr plog.LogRecord) string {
+	body := strings.TrimRight(lr.Body().AsString(), "\n")
+	sourceType := attribute(lr, "com.splunk.sourcetype")
+	if f.syslogSourceType != "" && sourceType == f.syslogSourceType {
+		return truncate(body, f.maxEventSize)
+	}
+	host := attribute(lr, "com.splunk.host")
+	if host == "" {
+		host = f.hostname
+	}
+	host = sanitize(host, maxHostnameLen)
+	t := lr.Timestamp()
+	if t == 0 {
+		t = lr.ObservedTimestamp()
+	}
+	timestamp := time.Now()
+	if t != 0 {
+		timestamp = t.AsTime()
+	}
+
+	var b strings.Builder
+	b.WriteString(f.priority)
+	if f.format == FormatRFC5424 {
+		appName := sanitize(sourceType, maxAppNameLen5424)
+		if host == "" {
+			host = "-"
+		}
+		if appName == "" {
+			appName = "-"
+		}
+		// Messages have no process ID, message ID nor structured data.
+		fmt.Fprintf(&b, "1 %s %s %s - - - %s", timestamp.UTC().Format(rfc5424Time), host, appName, body)
+		return truncate(b.String(), f.maxEventSize)
+	}
+	if f.timestampFormat != "" {
+		b.WriteString(strftime(f.timestampFormat, timestamp.In(f.location)))
+		b.WriteByte(' ')
+	}
+	if host != "" {
+		b.WriteString(host)
+		b.WriteByte(' ')
+	}
+	if tag := sanitize(strings.ReplaceAll(sourceType, ":", "_"), maxTagLen3164); tag != "" {
+		b.WriteString(tag)
+		b.WriteString(": ")
+	}
+	b.WriteString(body)
+	return truncate(b.String(), f.maxEventSize)
+}
+
+func attribute(lr plog.LogRecord, name string) string {
+	if v, ok := lr.Attributes().Get(name); ok {
+		return v.AsString()
+	}
+	return ""
+}
+
+// sanitize replaces the characters of a header field that are not printable ASCII characters, and truncates it.
+func sanitize(value string, maxLen int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' {
+			return '_'
+		}
+		return r
+	}, value)
+	return truncate(value, maxLen)
+}
+
+func truncate(value string, maxLen int) string {
+	if len(value) > maxLen {
+		return value[:maxLen]
+	}
+	return value
+}
+
+// strftime formats t according to a strftime format, as the timestampformat setting of outputs.conf.
+// Directives validateStrftime rejects are written as is.
+func strftime(format string, t time.Time) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i == len(format)-1 {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		if digits := format[i]; digits >= '1' && digits <= '9' && i+1 < len(format) && format[i+1] == 'N' {
+			// %<digits>N is the subsecond part of the time with that many digits, as in Splunk.
+			b.WriteString(fmt.Sprintf("%09d", t.Nanosecond())[:digits-'0'])
+			i++
+			continue
+		}
+		if layout, ok := strftimeLayouts[format[i]]; ok {
+			b.WriteString(t.Format(layout))
+			continue
+		}
+		switch format[i] {
+		case 'e':
+			fmt.Fprintf(&b, "%2d", t.Day())
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(format[i])
+		}
+	}
+	return b.String()
+}
+
+// strftimeLayouts are the time layouts of strftime directives.
+var strftimeLayouts = map[byte]string{
+	'a': "Mon",
+	'A': "Monday",
+	'b': "Jan",
+	'B': "January",
+	'd': "02",
+	'F': "2006-01-02",
+	'H': "15",
+	'I': "03",
+	'm': "01",
+	'M': "04",
+	'p': "PM",
+	'S': "05",
+	'T': "15:04:05",
+	'y': "06",
+	'Y': "2006",
+	'z': "-0700",
+	'Z': "MST",
+}
+
+// validateStrftime checks that strftime supports the directives of format.
+func validateStrftime(format string) error {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i == len(format)-1 {
+			return fmt.Errorf("%q ends with %%", format)
+		}
+		i++
+		c := format[i]
+		if c >= '1' && c <= '9' && i+1 < len(format) && format[i+1] == 'N' {
+			i++
+			continue
+		}
+		if _, ok := strftimeLayouts[c]; !ok && !strings.ContainsRune("ejs%", rune(c)) {
+			return fmt.Errorf("unsupported directive %%%c", c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package syslogexporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func newLogRecord(body string) plog.LogRecord {
+	lr := plog.NewLogRecord()
+	lr.Body().SetStr(body)
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Date(2023, 11, 4, 22, 13, 20, 250000000, time.UTC)))
+	lr.Attributes().PutStr("com.splunk.host", "web01")
+	lr.Attributes().PutStr("com.splunk.sourcetype", "linux:secure")
+	return lr
+}
+
+func TestMessage(t *testing.T) {
+	f := &formatter{format: FormatRFC3164, priority: "<13>", maxEventSize: 1024, hostname: "local", location: time.UTC}
+	assert.Equal(t, "<13>web01 linux_secure: sshd accepted", f.message(newLogRecord("sshd accepted\n")))
+
+	f.timestampFormat = "%b %e %H:%M:%S"
+	f.priority = ""
+	assert.Equal(t, "Nov  4 22:13:20 web01 linux_secure: sshd accepted", f.message(newLogRecord("sshd accepted")))
+
+	lr := newLogRecord("sshd accepted")
+	lr.Attributes().Remove("com.splunk.host")
+	lr.Attributes().Remove("com.splunk.sourcetype")
+	assert.Equal(t, "Nov  4 22:13:20 local sshd accepted", f.message(lr))
+
+	f = &formatter{format: FormatRFC5424, priority: "<34>", maxEventSize: 1024, hostname: "local", location: time.UTC}
+	assert.Equal(t, "<34>1 2023-11-04T22:13:20.250000Z web01 linux:secure - - - sshd accepted", f.message(newLogRecord("sshd accepted")))
+	lr = newLogRecord("multi\nline")
+	lr.Attributes().PutStr("com.splunk.host", "web 01")
+	lr.Attributes().Remove("com.splunk.sourcetype")
+	assert.Equal(t, "<34>1 2023-11-04T22:13:20.250000Z web_01 - - - - multi\nline", f.message(lr))
+
+	f.syslogSourceType = "linux:secure"
+	assert.Equal(t, "<38>Nov 4 sshd accepted", f.message(newLogRecord("<38>Nov 4 sshd accepted")))
+
+	f.maxEventSize = 10
+	assert.Equal(t, "<38>Nov 4 ", f.message(newLogRecord("<38>Nov 4 sshd accepted")))
+}
+
+func TestStrftime(t *testing.T) {
+	tm := time.Date(2023, 2, 3, 4, 5, 6, 123456789, time.UTC)
+	assert.Equal(t, "2023-02-03T04:05:06.123 +0000", strftime("%Y-%m-%dT%H:%M:%S.%3N %z", tm))
+	assert.Equal(t, "Fri Feb  3 04:05:06 034 1675397106 100%", strftime("%a %b %e %T %j %s 100%%", tm))
+
+	require.NoError(t, validateStrftime("%b %e %H:%M:%S.%6N"))
+	require.EqualError(t, validateStrftime("%Q"), "unsupported directive %Q")
+	require.EqualError(t, validateStrftime("%H%"), `"%H%" ends with %`)
+}
+
+func TestParsePriority(t *testing.T) {
+	for priority, header := range map[string]string{"<13>": "<13>", "<0>": "<0>", "NO_PRI": ""} {
+		h, err := parsePriority(priority)
+		require.NoError(t, err)
+		assert.Equal(t, header, h)
+	}
+	for _, priority := range []string{"13", "<192>", "<-1>", "<x>", ""} {
+		_, err := parsePriority(priority)
+		assert.Error(t, err, priority)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package metadata
+
+import (
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper"
+
+	"github.com/splunk/tarunner/internal/config"
+)
+
+const operatorType = "metadata"
+
+// NewConfig returns the configuration of an operator applying the metadata settings of tarunner.yaml.
+func NewConfig(operatorID string, m config.Metadata) *Config {
+	return &Config{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, operatorType),
+		Metadata:          m,
+	}
+}
+
+// Config is the configuration of a metadata operator.
+type Config struct {
+	helper.TransformerConfig `mapstructure:",squash"`
+	config.Metadata          `mapstructure:",squash"`
+}
+
+// Build builds a metadata operator.
+func (c Config) Build(set component.TelemetrySettings) (operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(set)
+	if err != nil {
+		return nil, err
+	}
+	return &Transformer{TransformerOperator: transformer, metadata: c.Metadata}, nil
+}
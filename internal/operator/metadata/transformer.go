@@ -0,0 +1,59 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metadata provides an operator renaming the index of entries, and setting the host, index and source
+// of entries without them, as set by tarunner.yaml.
+package metadata
+
+import (
+	"context"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper"
+
+	"github.com/splunk/tarunner/internal/config"
+)
+
+// Transformer renames the index attribute of entries, and sets the host, index and source attributes of entries
+// without them. An index left empty is removed, for the indexer to apply its default index.
+type Transformer struct {
+	helper.TransformerOperator
+	metadata config.Metadata
+}
+
+func (t *Transformer) ProcessBatch(ctx context.Context, entries []*entry.Entry) error {
+	return t.ProcessBatchWithTransform(ctx, entries, t.Transform)
+}
+
+func (t *Transformer) Process(ctx context.Context, entry *entry.Entry) error {
+	return t.ProcessWith(ctx, entry, t.Transform)
+}
+
+// Transform applies the metadata settings to an entry.
+func (t *Transformer) Transform(e *entry.Entry) error {
+	if e.Attributes == nil {
+		e.Attributes = map[string]any{}
+	}
+	index, _ := e.Attributes["index"].(string)
+	if renamed, ok := t.metadata.IndexRename[index]; ok {
+		index = renamed
+	}
+	if index == "" {
+		index = t.metadata.Index
+	}
+	if index == "" {
+		delete(e.Attributes, "index")
+	} else {
+		e.Attributes["index"] = index
+	}
+	setDefault(e, "host", t.metadata.Host)
+	setDefault(e, "source", t.metadata.Source)
+	return nil
+}
+
+// setDefault sets an attribute of an entry to value, unless the attribute is set or value is empty.
+func setDefault(e *entry.Entry, attribute string, value string) {
+	if current, _ := e.Attributes[attribute].(string); current == "" && value != "" {
+		e.Attributes[attribute] = value
+	}
+}
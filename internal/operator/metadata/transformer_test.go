@@ -0,0 +1,78 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package metadata
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/splunk/tarunner/internal/config"
+)
+
+func TestTransform(t *testing.T) {
+	op, err := NewConfig("metadata", config.Metadata{
+		Host:   "forwarder01",
+		Index:  "default",
+		Source: "tarunner",
+		IndexRename: map[string]string{
+			"os":      "linux_os",
+			"history": "",
+		},
+	}).Build(componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	transformer := op.(*Transformer)
+
+	tests := []struct {
+		name       string
+		attributes map[string]any
+		expected   map[string]any
+	}{
+		{
+			name:       "defaults",
+			attributes: nil,
+			expected:   map[string]any{"host": "forwarder01", "index": "default", "source": "tarunner"},
+		},
+		{
+			name:       "empty index",
+			attributes: map[string]any{"index": "", "host": "web01", "source": "/var/log/syslog"},
+			expected:   map[string]any{"host": "web01", "index": "default", "source": "/var/log/syslog"},
+		},
+		{
+			name:       "renamed index",
+			attributes: map[string]any{"index": "os"},
+			expected:   map[string]any{"host": "forwarder01", "index": "linux_os", "source": "tarunner"},
+		},
+		{
+			name:       "kept index",
+			attributes: map[string]any{"index": "main"},
+			expected:   map[string]any{"host": "forwarder01", "index": "main", "source": "tarunner"},
+		},
+		{
+			name:       "index renamed to the default",
+			attributes: map[string]any{"index": "history"},
+			expected:   map[string]any{"host": "forwarder01", "index": "default", "source": "tarunner"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := entry.New()
+			e.Attributes = tt.attributes
+			require.NoError(t, transformer.Transform(e))
+			assert.Equal(t, tt.expected, e.Attributes)
+		})
+	}
+
+	op, err = NewConfig("metadata", config.Metadata{
+		IndexRename: map[string]string{"history": ""},
+	}).Build(componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	e := entry.New()
+	e.Attributes = map[string]any{"index": "history", "host": "web01"}
+	require.NoError(t, op.(*Transformer).Transform(e))
+	assert.Equal(t, map[string]any{"host": "web01"}, e.Attributes)
+}
@@ -24,18 +24,7 @@ import (
 func CreateOperatorConfigs(pCfg conf.Prop, transforms []conf.Transform) []operator.Config {
 	var operators []operator.Config
 	start := noop.NewConfigWithID(fmt.Sprintf("%s-start", pCfg.Name))
-	switch pCfg.Type() {
-	case conf.SourceType:
-		start.IfExpr = fmt.Sprintf("attributes['sourcetype'] == %q", pCfg.Name)
-	case conf.Default:
-		// no condition
-	case conf.Source:
-		start.IfExpr = fmt.Sprintf("attributes['source'] == %q", pCfg.Name)
-	case conf.Host:
-		start.IfExpr = fmt.Sprintf("attributes['host'] == %q", pCfg.Name)
-	default:
-		panic(fmt.Sprintf("unknown prop type: %v", pCfg.Type()))
-	}
+	start.IfExpr = Condition(pCfg)
 	operators = append(operators, operator.NewConfig(start))
 	var previous *helper.WriterConfig
 	previous = &start.WriterConfig
@@ -88,3 +77,19 @@ func CreateOperatorConfigs(pCfg conf.Prop, transforms []conf.Transform) []operat
 
 	return operators
 }
+
+// Condition returns the expression matching the entries a props stanza applies to.
+func Condition(pCfg conf.Prop) string {
+	switch pCfg.Type() {
+	case conf.SourceType:
+		return fmt.Sprintf("attributes['sourcetype'] == %q", pCfg.Name)
+	case conf.Default:
+		return ""
+	case conf.Source:
+		return fmt.Sprintf("attributes['source'] == %q", pCfg.Name)
+	case conf.Host:
+		return fmt.Sprintf("attributes['host'] == %q", pCfg.Name)
+	default:
+		panic(fmt.Sprintf("unknown prop type: %v", pCfg.Type()))
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package preview runs events through the operators applying props.conf and transforms.conf,
+// and explains the changes made by each operator.
+package preview
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/transformer/noop"
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/splunk/tarunner/internal/conf"
+	"github.com/splunk/tarunner/internal/operator/prop"
+	"github.com/splunk/tarunner/internal/operator/transform"
+)
+
+// endID is the ID of the operator ending the pipeline, as in the receivers.
+const endID = "end"
+
+// Event is the content of an entry.
+type Event struct {
+	Body       any            `json:"body"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// Change is the change of a field of an event.
+type Change struct {
+	Field  string `json:"field"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// Step is the processing of an event by an operator.
+type Step struct {
+	Operator string `json:"operator"`
+	// Prop is the name of the props stanza the operator applies.
+	Prop string `json:"prop"`
+	// Class is the TRANSFORMS- class of the props stanza referencing the transform applied by the operator.
+	Class string `json:"class,omitempty"`
+	// Transform is the name of the transforms stanza applied by the operator.
+	Transform string `json:"transform,omitempty"`
+	// Matched tells whether the event matches the props stanza. Operators skip events that do not match.
+	Matched bool `json:"matched"`
+	// Condition is the expression matching the events the props stanza applies to.
+	Condition string `json:"condition,omitempty"`
+	// RegexMatched tells whether the regular expression of the transform matched the event.
+	RegexMatched *bool    `json:"regex_matched,omitempty"`
+	Changes      []Change `json:"changes,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// Result is the processing of an event by all operators.
+type Result struct {
+	Input  Event  `json:"input"`
+	Output Event  `json:"output"`
+	Steps  []Step `json:"steps,omitempty"`
+}
+
+// step is an operator of the pipeline.
+type step struct {
+	op        operator.Operator
+	next      string
+	prop      conf.Prop
+	class     string
+	transform string
+	regex     *regexp.Regexp
+}
+
+// Pipeline runs events through the operators built for props stanzas, one operator at a time.
+type Pipeline struct {
+	steps map[string]*step
+	first string
+	set   component.TelemetrySettings
+}
+
+// New builds the operators applying props and transforms, chained as in the receivers.
+func New(set component.TelemetrySettings, props []conf.Prop, transforms []conf.Transform) (*Pipeline, error) {
+	p := &Pipeline{steps: map[string]*step{}, set: set}
+	var ordered []*step
+	for _, pCfg := range props {
+		for _, cfg := range prop.CreateOperatorConfigs(pCfg, transforms) {
+			op, err := cfg.Build(set)
+			if err != nil {
+				return nil, fmt.Errorf("props stanza %q: %w", pCfg.Name, err)
+			}
+			s := &step{op: op, prop: pCfg}
+			if t, ok := cfg.Builder.(*transform.Config); ok {
+				s.regex, _ = regexp.Compile(t.Regex)
+				s.transform, s.class = transformOf(pCfg, transforms, op.ID())
+			}
+			ordered = append(ordered, s)
+		}
+	}
+	end, err := noop.NewConfigWithID(endID).Build(set)
+	if err != nil {
+		return nil, err
+	}
+	ordered = append(ordered, &step{op: end})
+	for i, s := range ordered {
+		if p.steps[s.op.ID()] != nil {
+			return nil, fmt.Errorf("duplicate operator %q", s.op.ID())
+		}
+		p.steps[s.op.ID()] = s
+		if ids := s.op.GetOutputIDs(); len(ids) > 0 {
+			s.next = ids[0]
+		} else if i+1 < len(ordered) {
+			// As in the receivers, operators without outputs write to the next operator.
+			s.next = ordered[i+1].op.ID()
+			s.op.SetOutputIDs([]string{s.next})
+		}
+	}
+	p.first = ordered[0].op.ID()
+	return p, nil
+}
+
+// transformOf returns the transforms stanza applied by the transform operator id, and the class referencing it.
+func transformOf(pCfg conf.Prop, transforms []conf.Transform, id string) (string, string) {
+	for _, t := range transforms {
+		if transform.NewConfig(pCfg.Name, t).OperatorID != id {
+			continue
+		}
+		for _, c := range pCfg.Transforms {
+			for _, stanza := range c.Stanza {
+				if stanza == t.Name {
+					return t.Name, c.Class
+				}
+			}
+		}
+		return t.Name, ""
+	}
+	return "", ""
+}
+
+// Run runs an entry through the operators, and returns the changes made by each operator.
+func (p *Pipeline) Run(ctx context.Context, e *entry.Entry) (Result, error) {
+	result := Result{Input: eventOf(e)}
+	for id := p.first; id != "" && id != endID; {
+		s, ok := p.steps[id]
+		if !ok {
+			return result, fmt.Errorf("unknown operator %q", id)
+		}
+		before := eventOf(e)
+		st := Step{
+			Operator:  id,
+			Prop:      s.prop.Name,
+			Class:     s.class,
+			Transform: s.transform,
+			Condition: prop.Condition(s.prop),
+			Matched:   true,
+		}
+		if skipper, ok := s.op.(interface {
+			Skip(context.Context, *entry.Entry) (bool, error)
+		}); ok {
+			skip, err := skipper.Skip(ctx, e)
+			if err != nil {
+				st.Error = err.Error()
+			}
+			st.Matched = !skip
+		}
+		if s.regex != nil && st.Matched {
+			body, _ := e.Body.(string)
+			matched := s.regex.MatchString(body)
+			st.RegexMatched = &matched
+		}
+
+		out, err := p.process(ctx, s, e)
+		if err != nil {
+			st.Error = err.Error()
+		}
+		if out == nil {
+			// The operator dropped the entry.
+			result.Steps = append(result.Steps, st)
+			result.Output = Event{}
+			return result, nil
+		}
+		e = out
+		st.Changes = diff(before, eventOf(e))
+		result.Steps = append(result.Steps, st)
+		id = s.next
+	}
+	result.Output = eventOf(e)
+	return result, nil
+}
+
+// process runs an entry through one operator, and returns the entry it outputs.
+func (p *Pipeline) process(ctx context.Context, s *step, e *entry.Entry) (*entry.Entry, error) {
+	c, err := newCapture(p.set, s.next)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.op.SetOutputs([]operator.Operator{c}); err != nil {
+		return nil, err
+	}
+	err = s.op.Process(ctx, e)
+	if len(c.entries) == 0 {
+		return nil, err
+	}
+	return c.entries[0], err
+}
+
+func eventOf(e *entry.Entry) Event {
+	ev := Event{Body: e.Body}
+	if len(e.Attributes) > 0 {
+		ev.Attributes = make(map[string]any, len(e.Attributes))
+		for k, v := range e.Attributes {
+			ev.Attributes[k] = v
+		}
+	}
+	return ev
+}
+
+// diff returns the changes of the body and attributes between two events.
+func diff(before, after Event) []Change {
+	var changes []Change
+	if !reflect.DeepEqual(before.Body, after.Body) {
+		changes = append(changes, Change{Field: "body", Before: before.Body, After: after.Body})
+	}
+	keys := map[string]bool{}
+	for k := range before.Attributes {
+		keys[k] = true
+	}
+	for k := range after.Attributes {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		b, a := before.Attributes[k], after.Attributes[k]
+		if !reflect.DeepEqual(b, a) {
+			changes = append(changes, Change{Field: "attributes." + k, Before: b, After: a})
+		}
+	}
+	return changes
+}
+
+// capture is an operator receiving the entries written by the operator being run.
+type capture struct {
+	helper.OutputOperator
+	entries []*entry.Entry
+}
+
+func newCapture(set component.TelemetrySettings, id string) (*capture, error) {
+	op, err := helper.NewOutputConfig(id, "capture").Build(set)
+	if err != nil {
+		return nil, err
+	}
+	return &capture{OutputOperator: op}, nil
+}
+
+func (c *capture) Start(operator.Persister) error {
+	return nil
+}
+
+func (c *capture) Stop() error {
+	return nil
+}
+
+func (c *capture) Process(_ context.Context, e *entry.Entry) error {
+	c.entries = append(c.entries, e)
+	return nil
+}
+
+func (c *capture) ProcessBatch(_ context.Context, entries []*entry.Entry) error {
+	c.entries = append(c.entries, entries...)
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package preview
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/featuregate"
+
+	"github.com/splunk/tarunner/internal/conf"
+	"github.com/splunk/tarunner/internal/featuregates"
+)
+
+func TestRun(t *testing.T) {
+	require.NoError(t, featuregate.GlobalRegistry().Set(featuregates.CookFeatureGate.ID(), true))
+	defer func() {
+		require.NoError(t, featuregate.GlobalRegistry().Set(featuregates.CookFeatureGate.ID(), false))
+	}()
+	props, err := conf.ReadProps([]byte(`[app]
+TRANSFORMS-user = user
+FIELDALIAS-usr = user as usr
+`))
+	require.NoError(t, err)
+	transforms, err := conf.ReadTransforms([]byte(`[user]
+REGEX = user=(?P<user>\w+)
+`))
+	require.NoError(t, err)
+	p, err := New(componenttest.NewNopTelemetrySettings(), props, transforms)
+	require.NoError(t, err)
+
+	e := entry.New()
+	e.Body = "login user=bob"
+	e.Attributes = map[string]any{"sourcetype": "app"}
+	result, err := p.Run(context.Background(), e)
+	require.NoError(t, err)
+	assert.Equal(t, Event{Body: "login user=bob", Attributes: map[string]any{"sourcetype": "app"}}, result.Input)
+	assert.Equal(t, Event{Body: "login user=bob", Attributes: map[string]any{
+		"sourcetype": "app",
+		"user":       "bob",
+		"usr":        "bob",
+	}}, result.Output)
+
+	var transformSteps []Step
+	for _, s := range result.Steps {
+		if s.Transform != "" {
+			transformSteps = append(transformSteps, s)
+		}
+	}
+	require.Len(t, transformSteps, 1)
+	assert.Equal(t, "app", transformSteps[0].Prop)
+	assert.Equal(t, "TRANSFORMS-user", transformSteps[0].Class)
+	assert.Equal(t, `attributes['sourcetype'] == "app"`, transformSteps[0].Condition)
+	assert.True(t, transformSteps[0].Matched)
+	require.NotNil(t, transformSteps[0].RegexMatched)
+	assert.True(t, *transformSteps[0].RegexMatched)
+	assert.Equal(t, []Change{{Field: "attributes.user", After: "bob"}}, transformSteps[0].Changes)
+}
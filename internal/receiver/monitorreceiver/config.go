@@ -4,7 +4,10 @@
 package monitorreceiver
 
 import (
+	"go.opentelemetry.io/collector/component"
+
 	"github.com/splunk/tarunner/internal/conf"
+	"github.com/splunk/tarunner/internal/config"
 )
 
 type Config struct {
@@ -13,4 +16,8 @@ type Config struct {
 
 	BaseDir string     `mapstructure:"-"`
 	Input   conf.Input `mapstructure:"-"`
+	// Metadata sets the default metadata of log records, and renames their index.
+	Metadata config.Metadata `mapstructure:"-"`
+	// StorageID, if set, is the storage extension keeping the offsets of the files read, flushed when the receiver stops.
+	StorageID *component.ID `mapstructure:"-"`
 }
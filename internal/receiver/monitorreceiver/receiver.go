@@ -12,6 +12,8 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/transformer/noop"
 
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/operator/metadata"
 	"github.com/splunk/tarunner/internal/operator/prop"
 
 	"github.com/splunk/tarunner/internal/script"
@@ -52,13 +54,14 @@ func (monitor) BaseConfig(cfg component.Config) adapter.BaseConfig {
 
 	endNoop := noop.NewConfigWithID("end")
 
-	metadata := renameMetadata()
+	metadata := renameMetadata(rcfg.Metadata)
 	endNoop.OutputIDs = []string{metadata[0].ID()}
 	operators = append(operators, operator.NewConfig(endNoop))
 	operators = append(operators, metadata...)
 
 	return adapter.BaseConfig{
 		Operators: operators,
+		StorageID: rcfg.StorageID,
 	}
 }
 
@@ -109,7 +112,12 @@ func (t monitor) InputConfig(config component.Config) operator.Config {
 	return operator.NewConfig(oc)
 }
 
-func renameMetadata() []operator.Config {
+// renameMetadata returns the operators applying the metadata settings of tarunner.yaml,
+// then moving the metadata attributes of entries to the attributes exporters read.
+func renameMetadata(m config.Metadata) []operator.Config {
+	defaults := metadata.NewConfig("end-metadata", m)
+	defaults.OutputIDs = []string{"end-source"}
+
 	source := move.NewConfigWithID("end-source")
 	source.From = entry.NewAttributeField("source")
 	source.To = entry.NewAttributeField("com.splunk.source")
@@ -126,7 +134,7 @@ func renameMetadata() []operator.Config {
 	host.From = entry.NewAttributeField("host")
 	host.To = entry.NewAttributeField("com.splunk.host")
 	host.OnError = "send_quiet"
-	sourceType.OutputIDs = []string{"end-index"}
+	host.OutputIDs = []string{"end-index"}
 
 	index := move.NewConfigWithID("end-index")
 	index.From = entry.NewAttributeField("index")
@@ -134,6 +142,7 @@ func renameMetadata() []operator.Config {
 	index.OnError = "send_quiet"
 
 	return []operator.Config{
+		operator.NewConfig(defaults),
 		operator.NewConfig(source),
 		operator.NewConfig(sourceType),
 		operator.NewConfig(host),
@@ -3,11 +3,25 @@
 
 package scriptreceiver
 
-import "github.com/splunk/tarunner/internal/conf"
+import (
+	"github.com/splunk/tarunner/internal/conf"
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/scriptedinput"
+)
 
 type Config struct {
 	BaseDir    string           `mapstructure:"-"`
 	Props      []conf.Prop      `mapstructure:"-"`
 	Transforms []conf.Transform `mapstructure:"-"`
+	// Times, if positive, runs the script this number of times instead of indefinitely.
+	Times int `mapstructure:"-"`
+	// Ran, if set, is called with the error of the last execution once the script ran Times times,
+	// or for the first time if Times is not set.
+	Ran func(error) `mapstructure:"-"`
+	// Metadata sets the default metadata of log records, and renames their index.
+	Metadata   config.Metadata `mapstructure:"-"`
 	conf.Input `mapstructure:"-"`
+
+	// stopping passes the context of the shutdown of the receiver to the scripted input.
+	stopping *scriptedinput.StopContext
 }
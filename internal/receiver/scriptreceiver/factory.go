@@ -4,11 +4,38 @@
 package scriptreceiver
 
 import (
+	"context"
+
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/adapter"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/splunk/tarunner/internal/scriptedinput"
 )
 
 func NewFactory() receiver.Factory {
-	return adapter.NewFactory(scriptReceiver{}, component.StabilityLevelAlpha)
+	f := adapter.NewFactory(scriptReceiver{}, component.StabilityLevelAlpha)
+	createLogs := func(ctx context.Context, set receiver.Settings, cfg component.Config, next consumer.Logs) (receiver.Logs, error) {
+		rcfg := *cfg.(*Config)
+		rcfg.stopping = &scriptedinput.StopContext{}
+		r, err := f.CreateLogs(ctx, set, &rcfg, next)
+		if err != nil {
+			return nil, err
+		}
+		return &stoppingReceiver{Logs: r, stopping: rcfg.stopping}, nil
+	}
+	return receiver.NewFactory(f.Type(), f.CreateDefaultConfig, receiver.WithLogs(createLogs, f.LogsStability()))
+}
+
+// stoppingReceiver passes the context of Shutdown to the scripted input, whose Stop method takes none,
+// so running scripts are given until the shutdown deadline to exit.
+type stoppingReceiver struct {
+	receiver.Logs
+	stopping *scriptedinput.StopContext
+}
+
+func (r *stoppingReceiver) Shutdown(ctx context.Context) error {
+	r.stopping.Set(ctx)
+	return r.Logs.Shutdown(ctx)
 }
@@ -13,6 +13,8 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/transformer/noop"
 	"go.opentelemetry.io/collector/component"
 
+	"github.com/splunk/tarunner/internal/config"
+	"github.com/splunk/tarunner/internal/operator/metadata"
 	"github.com/splunk/tarunner/internal/operator/prop"
 
 	"github.com/splunk/tarunner/internal/scriptedinput"
@@ -46,7 +48,7 @@ func (scriptReceiver) BaseConfig(cfg component.Config) adapter.BaseConfig {
 	}
 
 	endNoop := noop.NewConfigWithID("end")
-	metadata := renameMetadata()
+	metadata := renameMetadata(rcfg.Metadata)
 	endNoop.OutputIDs = []string{metadata[0].ID()}
 	operators = append(operators, operator.NewConfig(endNoop))
 	operators = append(operators, metadata...)
@@ -61,6 +63,9 @@ func (scriptReceiver) InputConfig(config component.Config) operator.Config {
 	oc := scriptedinput.NewConfig()
 	oc.Input = rcfg.Input
 	oc.BaseDir = rcfg.BaseDir
+	oc.Times = rcfg.Times
+	oc.Ran = rcfg.Ran
+	oc.Stopping = rcfg.stopping
 
 	oc.Attributes = map[string]helper.ExprStringConfig{}
 
@@ -92,7 +97,12 @@ func createSetSourceOperator() operator.Config {
 	return operator.NewConfig(c)
 }
 
-func renameMetadata() []operator.Config {
+// renameMetadata returns the operators applying the metadata settings of tarunner.yaml,
+// then moving the metadata attributes of entries to the attributes exporters read.
+func renameMetadata(m config.Metadata) []operator.Config {
+	defaults := metadata.NewConfig("end-metadata", m)
+	defaults.OutputIDs = []string{"end-source"}
+
 	source := move.NewConfigWithID("end-source")
 	source.From = entry.NewAttributeField("source")
 	source.To = entry.NewAttributeField("com.splunk.source")
@@ -109,7 +119,7 @@ func renameMetadata() []operator.Config {
 	host.From = entry.NewAttributeField("host")
 	host.To = entry.NewAttributeField("com.splunk.host")
 	host.OnError = "send_quiet"
-	sourceType.OutputIDs = []string{"end-index"}
+	host.OutputIDs = []string{"end-index"}
 
 	index := move.NewConfigWithID("end-index")
 	index.From = entry.NewAttributeField("index")
@@ -117,6 +127,7 @@ func renameMetadata() []operator.Config {
 	index.OnError = "send_quiet"
 
 	return []operator.Config{
+		operator.NewConfig(defaults),
 		operator.NewConfig(source),
 		operator.NewConfig(sourceType),
 		operator.NewConfig(host),
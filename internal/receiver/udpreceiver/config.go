@@ -7,6 +7,7 @@ import (
 	"net/url"
 
 	"github.com/splunk/tarunner/internal/conf"
+	"github.com/splunk/tarunner/internal/config"
 )
 
 type Config struct {
@@ -15,6 +16,8 @@ type Config struct {
 
 	BaseDir string     `mapstructure:"-"`
 	Input   conf.Input `mapstructure:"-"`
+	// Metadata sets the default metadata of log records, and renames their index.
+	Metadata config.Metadata `mapstructure:"-"`
 }
 
 func (cfg *Config) Validate() error {
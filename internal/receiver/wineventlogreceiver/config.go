@@ -5,6 +5,7 @@ package wineventlogreceiver
 
 import (
 	"github.com/splunk/tarunner/internal/conf"
+	"github.com/splunk/tarunner/internal/config"
 )
 
 type Config struct {
@@ -13,4 +14,6 @@ type Config struct {
 
 	BaseDir string     `mapstructure:"-"`
 	Input   conf.Input `mapstructure:"-"`
+	// Metadata sets the default metadata of log records, and renames their index.
+	Metadata config.Metadata `mapstructure:"-"`
 }
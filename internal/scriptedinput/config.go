@@ -30,7 +30,16 @@ func NewConfigWithID(operatorID string) *Config {
 }
 
 type Config struct {
-	BaseDir            string
+	BaseDir string
+	// Times, if positive, runs the script this number of times on its interval, then stops scheduling it.
+	Times int `mapstructure:"-"`
+	// Ran, if set, is called with the error of the last execution once the script ran Times times,
+	// or for the first time if Times is not set, and its output was written.
+	// It is called with a nil error when the input starts if the script is not scheduled.
+	Ran func(error) `mapstructure:"-"`
+	// Stopping, if set, holds the context of the shutdown stopping the input, whose deadline bounds how long
+	// Stop waits for the running script to exit before killing it.
+	Stopping           *StopContext `mapstructure:"-"`
 	conf.Input         `mapstructure:"-"`
 	helper.InputConfig `mapstructure:"-"`
 }
@@ -4,12 +4,14 @@
 package scriptedinput
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/url"
 	"os/exec"
 	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
@@ -23,26 +25,104 @@ import (
 	"github.com/splunk/tarunner/internal/conf"
 )
 
+const (
+	// stopGracePeriod is how long a script may run after being sent SIGTERM before it is killed,
+	// if the input is stopped without a shutdown deadline.
+	stopGracePeriod = 5 * time.Second
+	// outputWaitDelay is how long to wait for the output of a script to be closed once it exited.
+	outputWaitDelay = time.Second
+)
+
+// StopContext passes the context of a shutdown to the Stop method of inputs, which takes none.
+type StopContext struct {
+	mu  sync.Mutex
+	ctx context.Context
+}
+
+// Set sets the context of the shutdown.
+func (s *StopContext) Set(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+}
+
+// context returns the context set, and a context expiring after stopGracePeriod if none with a deadline was.
+func (s *StopContext) context() (context.Context, context.CancelFunc) {
+	if s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.ctx != nil {
+			if _, ok := s.ctx.Deadline(); ok {
+				return s.ctx, func() {}
+			}
+		}
+	}
+	return context.WithTimeout(context.Background(), stopGracePeriod)
+}
+
 type ScriptedInput struct {
 	logger   *zap.Logger
 	doneChan chan struct{}
 	command  *exec.Cmd
 	cfg      Config
 	helper.InputOperator
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	ranOnce  sync.Once
+	stopOnce sync.Once
 }
 
 func (si *ScriptedInput) Start(_ operator.Persister) error {
-	if _, err := si.scheduleInput(si.cfg.BaseDir, si.cfg.Input); err != nil {
+	scheduled, err := si.scheduleInput(si.cfg.BaseDir, si.cfg.Input)
+	if err != nil {
 		return err
 	}
+	if !scheduled {
+		si.ran(nil)
+	}
 	return nil
 }
 
+// ran calls the Ran callback of the configuration the first time it is called.
+func (si *ScriptedInput) ran(err error) {
+	if si.cfg.Ran != nil {
+		si.ranOnce.Do(func() {
+			si.cfg.Ran(err)
+		})
+	}
+}
+
+// Stop tells the running script to stop, and waits for it to exit and for its output to be consumed.
+// A script still running at the deadline of the Stopping context, or after stopGracePeriod without one, is killed.
+// Stop may be called several times.
 func (si *ScriptedInput) Stop() error {
+	si.mu.Lock()
+	si.stopOnce.Do(func() {
+		close(si.doneChan)
+	})
 	if si.command != nil {
 		_ = si.command.Process.Signal(syscall.SIGTERM)
 	}
-	close(si.doneChan)
+	si.mu.Unlock()
+
+	ctx, cancel := si.cfg.Stopping.context()
+	defer cancel()
+	stopped := make(chan struct{})
+	go func() {
+		si.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		si.mu.Lock()
+		if si.command != nil {
+			si.logger.Warn("Script did not stop in time, killing it", zap.String("input", si.cfg.Input.Configuration.Stanza.Name))
+			_ = si.command.Process.Kill()
+		}
+		si.mu.Unlock()
+		<-stopped
+	}
 
 	return nil
 }
@@ -77,41 +157,49 @@ func (si *ScriptedInput) scheduleScriptedInput(baseDir string, input conf.Input)
 		}
 	}
 	if intervalS == -1 {
-		return false, nil
+		if si.cfg.Times <= 0 {
+			return false, nil
+		}
+		// A script that is not scheduled still runs its number of times, one run after the other.
+		intervalS = 0
 	}
-	if intervalS == 0 {
-		go func() {
-			for {
+	interval := time.Duration(intervalS) * time.Second
+	si.wg.Add(1)
+	go func() {
+		defer si.wg.Done()
+		for n := 1; ; n++ {
+			err := si.execute(baseDir, input)
+			if n == si.cfg.Times || si.cfg.Times <= 0 {
+				si.ran(err)
+			}
+			if n == si.cfg.Times {
+				return
+			}
+			if interval == 0 {
+				// The script runs again as soon as it exits.
 				select {
 				case <-si.doneChan:
 					return
 				default:
-					si.execute(baseDir, input)
 				}
+				continue
 			}
-		}()
-	} else {
-		interval := time.Duration(intervalS) * time.Second
-		go func() {
-			si.execute(baseDir, input)
-
-			for {
-				select {
-				case <-time.After(interval):
-					si.execute(baseDir, input)
-				case <-si.doneChan:
-					return
-				}
+			select {
+			case <-time.After(interval):
+			case <-si.doneChan:
+				return
 			}
-		}()
-	}
+		}
+	}()
 	return true, nil
 }
 
-func (si *ScriptedInput) execute(baseDir string, input conf.Input) {
-	if err := si._execute(baseDir, input); err != nil {
+func (si *ScriptedInput) execute(baseDir string, input conf.Input) error {
+	err := si._execute(baseDir, input)
+	if err != nil {
 		si.logger.Error("Error executing input", zap.String("input", input.Configuration.Stanza.Name), zap.String("error", err.Error()))
 	}
+	return err
 }
 
 func (si *ScriptedInput) _execute(baseDir string, input conf.Input) error {
@@ -119,63 +207,53 @@ func (si *ScriptedInput) _execute(baseDir string, input conf.Input) error {
 	if err != nil {
 		return err
 	}
-	cmd := exec.Command(command)
-	var stdin io.WriteCloser
-	var stdout io.ReadCloser
-	if stdin, err = cmd.StdinPipe(); err != nil {
-		return err
-	}
-	if stdout, err = cmd.StdoutPipe(); err != nil {
-		return err
-	}
-
-	stopRead := make(chan struct{})
-	go func() {
-		for {
-			select {
-			case <-si.doneChan:
-				return
-			case <-stopRead:
-				return
-			default:
-				b, ioErr := io.ReadAll(stdout)
-				if len(b) == 0 {
-					return
-				}
-				e := entry.New()
-				e.Body = string(b)
-				if err := si.Attribute(e); err != nil {
-					si.logger.Error("Error setting attributes", zap.Error(err))
-				}
-
-				if err = si.Write(context.Background(), e); err != nil {
-					si.logger.Error("Error consuming logs", zap.Error(err))
-				}
-				if ioErr != nil {
-					return
-				}
-			}
-		}
-	}()
-
 	var inputXML []byte
 	if inputXML, err = input.ToXML(); err != nil {
 		return err
 	}
-	if _, err = stdin.Write(inputXML); err != nil {
-		return err
-	}
-	if err = stdin.Close(); err != nil {
-		return err
-	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(command)
+	cmd.Stdin = bytes.NewReader(inputXML)
+	// Wait returns once the output of the script is fully read.
+	cmd.Stdout = &stdout
+	// Do not wait for processes started by the script that keep its output open after it exits.
+	cmd.WaitDelay = outputWaitDelay
 
 	if err = cmd.Start(); err != nil {
 		return err
 	}
+	si.mu.Lock()
 	si.command = cmd
+	select {
+	case <-si.doneChan:
+		// the input was stopped while the script was starting.
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+	default:
+	}
+	si.mu.Unlock()
 
 	err = cmd.Wait()
-	close(stopRead)
+	if errors.Is(err, exec.ErrWaitDelay) {
+		// The script exited successfully, but a process it started still holds its output.
+		si.logger.Warn("Process started by the script keeps its output open, its later output is dropped",
+			zap.String("input", input.Configuration.Stanza.Name))
+		err = nil
+	}
+	si.mu.Lock()
+	si.command = nil
+	si.mu.Unlock()
+
+	if stdout.Len() > 0 {
+		e := entry.New()
+		e.Body = stdout.String()
+		if attrErr := si.Attribute(e); attrErr != nil {
+			si.logger.Error("Error setting attributes", zap.Error(attrErr))
+		}
+		if writeErr := si.Write(context.Background(), e); writeErr != nil {
+			si.logger.Error("Error consuming logs", zap.Error(writeErr))
+		}
+	}
 
 	return err
 }
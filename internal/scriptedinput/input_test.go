@@ -4,6 +4,7 @@
 package scriptedinput
 
 import (
+	"context"
 	"runtime"
 	"testing"
 	"time"
@@ -92,3 +93,131 @@ func Test_ScriptedInput(t *testing.T) {
 		})
 	}
 }
+
+func Test_ScriptedInputStopsRunningScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows because scripts use bash")
+	}
+
+	c := NewConfig()
+	c.BaseDir = "testdata"
+	c.Input = conf.Input{
+		Configuration: conf.Configuration{
+			Stanza: conf.Stanza{
+				Name: "script://./bin/sleep.sh",
+				Params: []conf.Param{
+					{Name: "interval", Value: "0"},
+				},
+			},
+		},
+	}
+	o, err := c.Build(componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	fo := testutil.NewFakeOutput(t)
+	o.SetOutputIDs([]string{fo.ID()})
+	require.NoError(t, o.SetOutputs([]operator.Operator{fo}))
+	require.NoError(t, o.Start(nil))
+
+	// wait for the script to run.
+	time.Sleep(200 * time.Millisecond)
+	start := time.Now()
+	require.NoError(t, o.Stop())
+	require.Less(t, time.Since(start), stopGracePeriod)
+	require.NoError(t, o.Stop())
+}
+
+func Test_ScriptedInputKillsScriptAtShutdownDeadline(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows because scripts use bash")
+	}
+
+	c := NewConfig()
+	c.BaseDir = "testdata"
+	c.Stopping = &StopContext{}
+	c.Input = conf.Input{
+		Configuration: conf.Configuration{
+			Stanza: conf.Stanza{
+				Name: "script://./bin/ignoreterm.sh",
+				Params: []conf.Param{
+					{Name: "interval", Value: "0"},
+				},
+			},
+		},
+	}
+	o, err := c.Build(componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	fo := testutil.NewFakeOutput(t)
+	o.SetOutputIDs([]string{fo.ID()})
+	require.NoError(t, o.SetOutputs([]operator.Operator{fo}))
+	require.NoError(t, o.Start(nil))
+
+	// wait for the script to run.
+	time.Sleep(200 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	c.Stopping.Set(ctx)
+	start := time.Now()
+	require.NoError(t, o.Stop())
+	require.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+	require.Less(t, time.Since(start), stopGracePeriod)
+}
+
+func Test_ScriptedInputKeepsOutputOfExitedScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows because scripts use bash")
+	}
+
+	tests := []struct {
+		name     string
+		script   string
+		runs     int
+		expected string
+	}{
+		{
+			// The output of a script exiting right after writing it used to be dropped.
+			name:     "quick exit",
+			script:   "script://./bin/quick.sh",
+			runs:     500,
+			expected: "line 1\nline 2\n",
+		},
+		{
+			// A process started by the script keeps its output open: the output is written once the script exited.
+			name:     "background process",
+			script:   "script://./bin/background.sh",
+			runs:     2,
+			expected: "started\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewConfig()
+			c.BaseDir = "testdata"
+			c.Input = conf.Input{
+				Configuration: conf.Configuration{
+					Stanza: conf.Stanza{
+						Name:   test.script,
+						Params: []conf.Param{{Name: "interval", Value: "0"}},
+					},
+				},
+			}
+			o, err := c.Build(componenttest.NewNopTelemetrySettings())
+			require.NoError(t, err)
+			fo := testutil.NewFakeOutput(t)
+			o.SetOutputIDs([]string{fo.ID()})
+			require.NoError(t, o.SetOutputs([]operator.Operator{fo}))
+			require.NoError(t, o.Start(nil))
+			t.Cleanup(func() {
+				require.NoError(t, o.Stop())
+			})
+
+			for i := 0; i < test.runs; i++ {
+				select {
+				case e := <-fo.Received:
+					require.Equal(t, test.expected, e.Body)
+				case <-time.After(5 * time.Second):
+					require.Failf(t, "timed out waiting for output", "run %d", i+1)
+				}
+			}
+		})
+	}
+}
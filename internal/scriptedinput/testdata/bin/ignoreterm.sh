@@ -0,0 +1,5 @@
+#!/bin/bash
+
+trap "" TERM
+echo "ignoring SIGTERM"
+exec sleep 30
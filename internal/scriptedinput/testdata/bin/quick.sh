@@ -0,0 +1,4 @@
+#!/bin/bash
+
+echo "line 1"
+echo "line 2"
@@ -0,0 +1,4 @@
+#!/bin/bash
+
+echo "sleeping"
+sleep 30
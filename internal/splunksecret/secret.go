@@ -0,0 +1,132 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package splunksecret encrypts and decrypts secrets of Splunk configuration files with the key of a splunk.secret file:
+// $7$ values, encrypted with AES-GCM since Splunk 7.2, and legacy $1$ values, encrypted with RC4.
+package splunksecret
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/rc4"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Prefixes of encrypted values.
+const (
+	prefixAESGCM = "$7$"
+	prefixRC4    = "$1$"
+)
+
+// legacySalt is XORed with the plaintext of $1$ values.
+const legacySalt = "DEFAULTSA"
+
+// Secret is the key of a splunk.secret file.
+type Secret []byte
+
+// Read reads a splunk.secret file.
+func Read(path string) (Secret, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secret := Secret(bytes.TrimSpace(b))
+	if len(secret) < 16 {
+		return nil, fmt.Errorf("%s: a splunk.secret file holds at least 16 characters", path)
+	}
+	return secret, nil
+}
+
+// IsEncrypted returns true if value is encrypted with a splunk.secret key.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefixAESGCM) || strings.HasPrefix(value, prefixRC4)
+}
+
+// Decrypt decrypts a $7$ or $1$ value. Other values are returned as is.
+func (s Secret) Decrypt(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, prefixAESGCM):
+		data, err := base64.StdEncoding.DecodeString(value[len(prefixAESGCM):])
+		if err != nil {
+			return "", fmt.Errorf("invalid $7$ value: %w", err)
+		}
+		gcm, err := s.gcm()
+		if err != nil {
+			return "", err
+		}
+		if len(data) < gcm.NonceSize()+gcm.Overhead() {
+			return "", errors.New("invalid $7$ value: too short")
+		}
+		plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+		if err != nil {
+			return "", errors.New("failed to decrypt $7$ value: not encrypted with this splunk.secret")
+		}
+		return string(plaintext), nil
+	case strings.HasPrefix(value, prefixRC4):
+		data, err := base64.StdEncoding.DecodeString(value[len(prefixRC4):])
+		if err != nil {
+			return "", fmt.Errorf("invalid $1$ value: %w", err)
+		}
+		c, err := rc4.NewCipher(s[:16])
+		if err != nil {
+			return "", err
+		}
+		c.XORKeyStream(data, data)
+		// The plaintext ends with a NUL byte.
+		plaintext, ok := bytes.CutSuffix(data, []byte{0})
+		if !ok {
+			return "", errors.New("failed to decrypt $1$ value: not encrypted with this splunk.secret")
+		}
+		return string(salt(plaintext)), nil
+	default:
+		return value, nil
+	}
+}
+
+// Encrypt encrypts plaintext as a $7$ value.
+func (s Secret) Encrypt(plaintext string) (string, error) {
+	gcm, err := s.gcm()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return prefixAESGCM + base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+// gcm returns the AES-GCM cipher of $7$ values, whose key is derived from the first 254 bytes of the secret,
+// and whose nonce is 16 bytes long.
+func (s Secret) gcm() (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, string(s[:min(len(s), 254)]), []byte("disk-encryption"), 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCMWithNonceSize(block, 16)
+}
+
+// salt XORs the bytes of b with the legacy salt, but for the bytes equal to their salt byte, which would be 0.
+// The function is its own inverse.
+func salt(b []byte) []byte {
+	salted := make([]byte, len(b))
+	for i, c := range b {
+		if s := legacySalt[i%len(legacySalt)]; c != s {
+			c ^= s
+		}
+		salted[i] = c
+	}
+	return salted
+}
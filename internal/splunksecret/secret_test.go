@@ -0,0 +1,73 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package splunksecret
+
+import (
+	"crypto/rc4"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSecret(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "splunk.secret")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o400))
+	return path
+}
+
+// encryptLegacy encrypts plaintext as a $1$ value, as Splunk versions before 7.2.
+func encryptLegacy(t *testing.T, s Secret, plaintext string) string {
+	c, err := rc4.NewCipher(s[:16])
+	require.NoError(t, err)
+	data := append(salt([]byte(plaintext)), 0)
+	c.XORKeyStream(data, data)
+	return prefixRC4 + base64.StdEncoding.EncodeToString(data)
+}
+
+func TestDecrypt(t *testing.T) {
+	secret, err := Read(writeSecret(t, strings.Repeat("0123456789abcdef", 16)[:255]+"\n"))
+	require.NoError(t, err)
+	assert.Len(t, secret, 255)
+
+	encrypted, err := secret.Encrypt("changeme")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, "$7$"))
+	assert.True(t, IsEncrypted(encrypted))
+	decrypted, err := secret.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "changeme", decrypted)
+
+	// Bytes equal to their salt byte are kept as is.
+	legacy := encryptLegacy(t, secret, "DEFAULT password")
+	assert.True(t, IsEncrypted(legacy))
+	decrypted, err = secret.Decrypt(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, "DEFAULT password", decrypted)
+
+	decrypted, err = secret.Decrypt("plain")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", decrypted)
+	assert.False(t, IsEncrypted("plain"))
+
+	other := Secret(strings.Repeat("x", 255))
+	_, err = other.Decrypt(encrypted)
+	require.EqualError(t, err, "failed to decrypt $7$ value: not encrypted with this splunk.secret")
+	_, err = secret.Decrypt("$7$AAAA")
+	require.EqualError(t, err, "invalid $7$ value: too short")
+	_, err = secret.Decrypt("$1$%")
+	require.ErrorContains(t, err, "invalid $1$ value: ")
+}
+
+func TestRead(t *testing.T) {
+	path := writeSecret(t, "short\n")
+	_, err := Read(path)
+	require.EqualError(t, err, path+": a splunk.secret file holds at least 16 characters")
+	_, err = Read(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
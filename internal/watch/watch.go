@@ -0,0 +1,92 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// Watch watches files and directories and calls onChange once changes to them have settled for the debounce duration.
+// Paths are watched through their parent directory, so files replaced by editors and directories created
+// after the watch started are picked up.
+// The function blocks until ctx is done.
+func Watch(ctx context.Context, logger *zap.Logger, paths []string, debounce time.Duration, onChange func()) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = w.Close()
+	}()
+
+	watched := map[string]bool{}
+	add := func(p string) {
+		if watched[p] {
+			return
+		}
+		if err := w.Add(p); err != nil {
+			logger.Debug("Cannot watch path", zap.String("path", p), zap.Error(err))
+			return
+		}
+		watched[p] = true
+	}
+
+	cleaned := make([]string, len(paths))
+	for i, p := range paths {
+		cleaned[i] = filepath.Clean(p)
+		add(filepath.Dir(cleaned[i]))
+		if isDir(cleaned[i]) {
+			add(cleaned[i])
+		}
+	}
+
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Warn("Error watching configuration files", zap.Error(err))
+		case event, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if !matches(cleaned, event.Name) {
+				continue
+			}
+			if event.Has(fsnotify.Create) && slices.Contains(cleaned, event.Name) && isDir(event.Name) {
+				add(event.Name)
+			}
+			timer = time.After(debounce)
+		case <-timer:
+			timer = nil
+			onChange()
+		}
+	}
+}
+
+func matches(paths []string, name string) bool {
+	for _, p := range paths {
+		if name == p || strings.HasPrefix(name, p+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,60 @@
+// Copyright Splunk, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "tarunner.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("type: otlp_http"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unrelated"), []byte("foo"), 0o600))
+	localDir := filepath.Join(dir, "local")
+
+	var changes atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, Watch(ctx, zap.NewNop(), []string{configFile, localDir}, 10*time.Millisecond, func() {
+			changes.Add(1)
+		}))
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	// let the watcher register its watches.
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unrelated"), []byte("bar"), 0o600))
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, int32(0), changes.Load())
+
+	require.NoError(t, os.WriteFile(configFile, []byte("type: splunk_hec"), 0o600))
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		assert.Equal(tt, int32(1), changes.Load())
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.Mkdir(localDir, 0o700))
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		assert.Equal(tt, int32(2), changes.Load())
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "inputs.conf"), []byte("[script://./bin/foo.sh]"), 0o600))
+	require.EventuallyWithT(t, func(tt *assert.CollectT) {
+		assert.Equal(tt, int32(3), changes.Load())
+	}, 2*time.Second, 10*time.Millisecond)
+}